@@ -0,0 +1,228 @@
+// Package benders solves the routing and spectrum assignment problem by
+// logic-based Benders decomposition. The master problem is a MILP that
+// accepts requests and picks their UsePath/UseMod, i.e. one reach-feasible
+// route each, subject to per-link spectrum capacity. The subproblem fixes
+// that routing and searches for a spectrum assignment inside the zones on
+// the conflict graph. When there is none, a minimal set of routes that
+// cannot be assigned together is cut off from the master by a no-good cut
+// and the master is solved again.
+package benders
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/dilwar-crnlab/hpsr_2025/conflict"
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+	"github.com/dilwar-crnlab/hpsr_2025/milp"
+	"github.com/dilwar-crnlab/hpsr_2025/model"
+	"github.com/dilwar-crnlab/hpsr_2025/plan"
+	"github.com/dilwar-crnlab/hpsr_2025/spectrum"
+)
+
+// Options limits the effort of the decomposition.
+type Options struct {
+	Budget   int       // nodes per master solve and per subproblem, <= 0 for no limit
+	Deadline time.Time // zero for none
+	Log      io.Writer // if not nil, receives one line per iteration
+}
+
+// Result is the outcome of the decomposition.
+type Result struct {
+	Plan       plan.Plan
+	Optimal    bool
+	Bound      float64 // upper bound on the accepted weight
+	Iterations int
+	Cuts       int
+	Nodes      int // branch-and-bound nodes of all master solves
+}
+
+// choice is a route variable of the master.
+type choice struct {
+	t instance.Demand
+	r plan.Route
+}
+
+type master struct {
+	in      *instance.Instance
+	m       *model.MILP
+	accept  map[instance.Demand]int
+	choices []choice
+	vars    []int // per choice
+}
+
+// newMaster builds the master MILP. Routes whose block fits in no zone are
+// left out. Every link carries at most N_slots + G slots of blocks widened
+// by their guard band, since blocks sharing a link are G slots apart. The
+// objective is the accepted weight, with ties broken towards fewer slots.
+func newMaster(in *instance.Instance) *master {
+	ms := &master{in: in, m: model.NewMILP("benders"), accept: map[instance.Demand]int{}}
+	widest := 0
+	for _, z := range spectrum.Zones(in) {
+		widest = max(widest, z.Width())
+	}
+	load := map[instance.Link][]model.Term{}
+	total := 0
+	for _, t := range in.Traffic {
+		for _, r := range plan.Candidates(in, t) {
+			if n := plan.Slots(in, t, r); n <= widest {
+				ms.choices = append(ms.choices, choice{t, r})
+				total += n
+			}
+		}
+	}
+	minW := 0.0
+	for _, t := range in.Traffic {
+		if w := in.Weight(t); minW == 0 || w < minW {
+			minW = w
+		}
+	}
+	eps := 1e-3 * minW / float64(max(total, 1))
+	byT := map[instance.Demand][]model.Term{}
+	for _, c := range ms.choices {
+		a, ok := ms.accept[c.t]
+		if !ok {
+			a = ms.m.AddVar(fmt.Sprintf("Accept(%s,%s)", c.t.S, c.t.D), model.Binary, 0, 1)
+			ms.accept[c.t] = a
+			ms.m.Maximize(model.Term{Var: a, Coef: in.Weight(c.t)})
+			byT[c.t] = []model.Term{{Var: a, Coef: -1}}
+		}
+		n := plan.Slots(in, c.t, c.r)
+		v := ms.m.AddVar(fmt.Sprintf("UseMod(%s,%s,%s,%s)", c.t.S, c.t.D, c.r.P, c.r.M), model.Binary, 0, 1)
+		ms.vars = append(ms.vars, v)
+		ms.m.Maximize(model.Term{Var: v, Coef: -eps * float64(n)})
+		byT[c.t] = append(byT[c.t], model.Term{Var: v, Coef: 1})
+		for _, l := range plan.Links(in, c.t, c.r) {
+			load[l] = append(load[l], model.Term{Var: v, Coef: float64(n + in.G)})
+		}
+	}
+	for _, t := range in.Traffic {
+		if terms, ok := byT[t]; ok {
+			ms.m.AddRow(fmt.Sprintf("OneRoute(%s,%s)", t.S, t.D), terms, model.EQ, 0)
+		}
+	}
+	for _, l := range in.Links {
+		if terms := load[l]; len(terms) > 1 {
+			ms.m.AddRow(fmt.Sprintf("Capacity(%s)", l), terms, model.LE, float64(in.NSlots+in.G))
+		}
+	}
+	return ms
+}
+
+// routing returns the routes chosen in x.
+func (ms *master) routing(x []float64) []choice {
+	var cs []choice
+	for i, c := range ms.choices {
+		if x[ms.vars[i]] > 0.5 {
+			cs = append(cs, c)
+		}
+	}
+	return cs
+}
+
+// cut forbids choosing all routes of cs together.
+func (ms *master) cut(k int, cs []choice) {
+	var terms []model.Term
+	for _, c := range cs {
+		for i, d := range ms.choices {
+			if d == c {
+				terms = append(terms, model.Term{Var: ms.vars[i], Coef: 1})
+			}
+		}
+	}
+	ms.m.AddRow(fmt.Sprintf("NoGood(%d)", k), terms, model.LE, float64(len(cs)-1))
+}
+
+// assign is the subproblem: a spectrum assignment of the routes cs inside
+// the zones. proven is false when the search ran out of budget.
+func assign(in *instance.Instance, cs []choice, budget int) (p plan.Plan, found, proven bool) {
+	rt := map[instance.Demand]plan.Route{}
+	for _, c := range cs {
+		rt[c.t] = c.r
+	}
+	return conflict.Build(in, rt).Feasible(budget)
+}
+
+// Solve runs the decomposition on in. The DSatur plan of the shortest
+// routes is the initial incumbent; the search stops when the master bound reaches the
+// incumbent's weight or the routing of the master can be assigned. It also
+// stops, without proving optimality, when the subproblem runs out of budget
+// before deciding a routing, as a cut could then remove feasible routings.
+func Solve(in *instance.Instance, opt Options) Result {
+	ms := newMaster(in)
+	res := Result{Plan: conflict.Build(in, plan.Shortest(in)).DSatur()}
+	for t := range ms.accept {
+		res.Bound += in.Weight(t)
+	}
+	improve := func(p plan.Plan) {
+		if p.Weight(in) > res.Plan.Weight(in) {
+			res.Plan = p
+		}
+	}
+	for {
+		res.Iterations++
+		r := milp.Solve(ms.m, milp.Options{NodeLimit: opt.Budget, Deadline: opt.Deadline})
+		res.Nodes += r.Nodes
+		if r.Status != milp.Optimal {
+			// Cuts only tighten the master, so the last bound still holds.
+			return res
+		}
+		res.Bound = bound(in, ms, r.X)
+		cs := ms.routing(r.X)
+		p, found, proven := assign(in, cs, opt.Budget)
+		if opt.Log != nil {
+			fmt.Fprintf(opt.Log, "iteration %d: bound %g, %d routes, assignable %v, incumbent %g\n",
+				res.Iterations, res.Bound, len(cs), found, res.Plan.Weight(in))
+		}
+		if found {
+			improve(p)
+			res.Optimal = true
+			return res
+		}
+		if res.Bound <= res.Plan.Weight(in)+1e-9 {
+			res.Optimal = true
+			return res
+		}
+		if !proven {
+			return res
+		}
+		core := minimize(in, cs, opt.Budget, improve)
+		res.Cuts++
+		ms.cut(res.Cuts, core)
+		if !opt.Deadline.IsZero() && time.Now().After(opt.Deadline) {
+			return res
+		}
+	}
+}
+
+// bound is the accepted weight of the master solution x, without the tie
+// breaking terms.
+func bound(in *instance.Instance, ms *master, x []float64) float64 {
+	var w float64
+	for t, a := range ms.accept {
+		w += in.Weight(t) * x[a]
+	}
+	return w
+}
+
+// minimize shrinks an unassignable routing to a minimal unassignable core
+// by deletion: a route is dropped for good when the rest stays provably
+// unassignable. Assignable subsets found on the way are offered to improve.
+func minimize(in *instance.Instance, cs []choice, budget int, improve func(plan.Plan)) []choice {
+	core := append([]choice(nil), cs...)
+	for i := 0; i < len(core); {
+		rest := append(append([]choice(nil), core[:i]...), core[i+1:]...)
+		p, found, proven := assign(in, rest, budget)
+		switch {
+		case found:
+			improve(p)
+			i++
+		case proven:
+			core = rest
+		default:
+			i++
+		}
+	}
+	return core
+}
@@ -0,0 +1,48 @@
+package benders
+
+import (
+	"testing"
+
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+)
+
+func TestSolve(t *testing.T) {
+	tests := []struct {
+		name   string
+		slots  int
+		budget int
+		weight float64
+	}{
+		{"line4 in 7", 7, 0, 4},
+		{"line4 in 6", 6, 0, 2},
+		{"line4 in 20", 20, 0, 4},
+		// With a tiny budget the subproblems go undecided, and a routing
+		// left undecided must not be cut off.
+		{"line4 in 7, budget 2", 7, 2, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in, err := instance.Load("../testdata/line4.dat")
+			if err != nil {
+				t.Fatal(err)
+			}
+			in.ScaleZones(tt.slots)
+			res := Solve(in, Options{Budget: tt.budget})
+			if err := res.Plan.Validate(in); err != nil {
+				t.Fatalf("invalid plan: %v", err)
+			}
+			w := res.Plan.Weight(in)
+			switch {
+			case tt.budget <= 0 && !res.Optimal:
+				t.Errorf("not proven optimal")
+			case res.Optimal && w != tt.weight:
+				t.Errorf("weight %g reported optimal, want %g", w, tt.weight)
+			case w > tt.weight:
+				t.Errorf("weight %g above the optimum %g", w, tt.weight)
+			}
+			if res.Bound < tt.weight-1e-9 {
+				t.Errorf("bound %g below the optimum %g", res.Bound, tt.weight)
+			}
+		})
+	}
+}
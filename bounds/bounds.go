@@ -0,0 +1,115 @@
+// Package bounds computes quick bounds on the spectrum (number of slots,
+// S_max) needed to accept every request of an instance.
+package bounds
+
+import "github.com/dilwar-crnlab/hpsr_2025/instance"
+
+// SlotRange returns the fewest and the most slots N_req that t needs over
+// its reach-feasible (p,m) combinations. ok is false when t has none.
+func SlotRange(in *instance.Instance, t instance.Demand) (lo, hi int, ok bool) {
+	for _, p := range in.Paths[t] {
+		d := in.PathDist(t, p)
+		for _, m := range in.FeasMod[instance.PathKey{T: t, P: p}] {
+			if d > in.R[m] {
+				continue
+			}
+			n := in.NReq[instance.ModKey{T: t, P: p, M: m}]
+			if !ok || n < lo {
+				lo = n
+			}
+			if !ok || n > hi {
+				hi = n
+			}
+			ok = true
+		}
+	}
+	return lo, hi, ok
+}
+
+// Unroutable lists the requests without any reach-feasible (p,m).
+func Unroutable(in *instance.Instance) []instance.Demand {
+	var ts []instance.Demand
+	for _, t := range in.Traffic {
+		if _, _, ok := SlotRange(in, t); !ok {
+			ts = append(ts, t)
+		}
+	}
+	return ts
+}
+
+// pack is the spectrum taken by blocks of the given sizes laid side by side
+// with a guard band of G slots between neighbours.
+func pack(in *instance.Instance, sizes []int) int {
+	if len(sizes) == 0 {
+		return 0
+	}
+	n := in.G * (len(sizes) - 1)
+	for _, s := range sizes {
+		n += s
+	}
+	return n
+}
+
+// Disjoint bounds S_max under the SpectrumNonOverlap constraints of
+// ilp.mod, which keep the blocks of every pair of accepted requests apart
+// whether or not they share a link. Packing the smallest blocks is a lower
+// bound; packing the largest ones always fits and is an upper bound.
+// Unroutable requests are left out.
+func Disjoint(in *instance.Instance) (lower, upper int) {
+	var lo, hi []int
+	for _, t := range in.Traffic {
+		if l, h, ok := SlotRange(in, t); ok {
+			lo = append(lo, l)
+			hi = append(hi, h)
+		}
+	}
+	return pack(in, lo), pack(in, hi)
+}
+
+// LinkLoad is a lower bound on S_max when only requests sharing a link must
+// be spectrally disjoint: the requests all of whose reach-feasible options
+// use link l must fit side by side on l. It returns the bound and the
+// bottleneck link.
+func LinkLoad(in *instance.Instance) (int, instance.Link) {
+	best, bestLink := 0, instance.Link{}
+	for _, l := range in.Links {
+		var sizes []int
+		for _, t := range in.Traffic {
+			lo, _, ok := SlotRange(in, t)
+			if ok && alwaysUses(in, t, l) {
+				sizes = append(sizes, lo)
+			}
+		}
+		if n := pack(in, sizes); n > best {
+			best, bestLink = n, l
+		}
+	}
+	return best, bestLink
+}
+
+// alwaysUses reports whether every reach-feasible option of t crosses l.
+func alwaysUses(in *instance.Instance, t instance.Demand, l instance.Link) bool {
+	for _, p := range in.Paths[t] {
+		k := instance.PathKey{T: t, P: p}
+		d := in.PathDist(t, p)
+		feasible := false
+		for _, m := range in.FeasMod[k] {
+			if d <= in.R[m] {
+				feasible = true
+			}
+		}
+		if feasible && !uses(in, in.PathLinks[k], l) {
+			return false
+		}
+	}
+	return true
+}
+
+func uses(in *instance.Instance, path []instance.Link, l instance.Link) bool {
+	for _, pl := range path {
+		if in.Canonical(pl) == in.Canonical(l) {
+			return true
+		}
+	}
+	return false
+}
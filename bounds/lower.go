@@ -0,0 +1,76 @@
+package bounds
+
+import (
+	"github.com/dilwar-crnlab/hpsr_2025/conflict"
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+	"github.com/dilwar-crnlab/hpsr_2025/plan"
+)
+
+// AverageLoad is a lower bound on S_max for any routing: every request
+// needs at least the smallest slots x hops product among its routes, and
+// the links together offer |LINKS| x S_max slot-links. Guard bands are
+// ignored.
+func AverageLoad(in *instance.Instance) int {
+	if len(in.Links) == 0 {
+		return 0
+	}
+	total := 0
+	for _, t := range in.Traffic {
+		best := -1
+		for _, r := range plan.Candidates(in, t) {
+			n := plan.Slots(in, t, r) * len(in.PathLinks[instance.PathKey{T: t, P: r.P}])
+			if best < 0 || n < best {
+				best = n
+			}
+		}
+		if best > 0 {
+			total += best
+		}
+	}
+	return (total + len(in.Links) - 1) / len(in.Links)
+}
+
+// RoutedLoad is the highest link load of routing rt: the requests crossing
+// a link laid side by side with guard bands. It bounds S_max from below
+// for any spectrum assignment of rt.
+func RoutedLoad(in *instance.Instance, rt map[instance.Demand]plan.Route) (int, instance.Link) {
+	load := map[instance.Link][]int{}
+	for _, t := range in.Traffic {
+		r, ok := rt[t]
+		if !ok {
+			continue
+		}
+		for _, l := range plan.Links(in, t, r) {
+			load[l] = append(load[l], plan.Slots(in, t, r))
+		}
+	}
+	best, bestLink := 0, instance.Link{}
+	for _, l := range in.Links {
+		if n := pack(in, load[l]); n > best {
+			best, bestLink = n, l
+		}
+	}
+	return best, bestLink
+}
+
+// Clique bounds S_max for routing rt by the heaviest clique of its conflict
+// graph: pairwise conflicting requests need disjoint blocks even when no
+// single link carries them all. It returns the bound, the clique and
+// whether the clique search was exhaustive (the bound is valid either way).
+func Clique(in *instance.Instance, rt map[instance.Demand]plan.Route, budget int) (int, []instance.Demand, bool) {
+	g := conflict.Build(in, rt)
+	c, exact := g.MaxWeightClique(budget)
+	ts := make([]instance.Demand, len(c))
+	for i, v := range c {
+		ts[i] = g.T[v]
+	}
+	return g.Span(c), ts, exact
+}
+
+// Gap is the relative gap (smax - lower) / smax.
+func Gap(smax, lower int) float64 {
+	if smax == 0 {
+		return 0
+	}
+	return float64(smax-lower) / float64(smax)
+}
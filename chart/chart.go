@@ -0,0 +1,164 @@
+// Package chart draws simple line charts and heatmaps as standalone SVG
+// files, enough to look at parameter sweeps without a plotting stack.
+package chart
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"math"
+)
+
+const (
+	width, height = 640, 420
+	left, right   = 70, 150
+	top, bottom   = 40, 60
+)
+
+// Series is one curve of a line chart.
+type Series struct {
+	Name string
+	X, Y []float64
+}
+
+var palette = []string{"#1f77b4", "#d62728", "#2ca02c", "#ff7f0e", "#9467bd", "#8c564b", "#e377c2", "#7f7f7f"}
+
+// Lines draws the series as polylines with markers over common axes.
+// Points with a NaN coordinate are skipped.
+func Lines(w io.Writer, title, xlabel, ylabel string, series []Series) error {
+	xlo, xhi, ylo, yhi := math.Inf(1), math.Inf(-1), math.Inf(1), math.Inf(-1)
+	for _, s := range series {
+		for i := range s.X {
+			if math.IsNaN(s.X[i]) || math.IsNaN(s.Y[i]) {
+				continue
+			}
+			xlo, xhi = math.Min(xlo, s.X[i]), math.Max(xhi, s.X[i])
+			ylo, yhi = math.Min(ylo, s.Y[i]), math.Max(yhi, s.Y[i])
+		}
+	}
+	if math.IsInf(xlo, 1) {
+		xlo, xhi, ylo, yhi = 0, 1, 0, 1
+	}
+	xlo, xhi = pad(xlo, xhi)
+	ylo, yhi = pad(ylo, yhi)
+	px := func(x float64) float64 { return left + (x-xlo)/(xhi-xlo)*(width-left-right) }
+	py := func(y float64) float64 { return height - bottom - (y-ylo)/(yhi-ylo)*(height-top-bottom) }
+
+	bw := bufio.NewWriter(w)
+	header(bw, title, xlabel, ylabel)
+	axes(bw, xlo, xhi, ylo, yhi, px, py)
+	for i, s := range series {
+		col := palette[i%len(palette)]
+		fmt.Fprintf(bw, `<polyline fill="none" stroke="%s" stroke-width="2" points="`, col)
+		for j := range s.X {
+			if !math.IsNaN(s.X[j]) && !math.IsNaN(s.Y[j]) {
+				fmt.Fprintf(bw, "%.1f,%.1f ", px(s.X[j]), py(s.Y[j]))
+			}
+		}
+		fmt.Fprintln(bw, `"/>`)
+		for j := range s.X {
+			if !math.IsNaN(s.X[j]) && !math.IsNaN(s.Y[j]) {
+				fmt.Fprintf(bw, `<circle cx="%.1f" cy="%.1f" r="3" fill="%s"/>`+"\n", px(s.X[j]), py(s.Y[j]), col)
+			}
+		}
+		y := top + 20*i
+		fmt.Fprintf(bw, `<rect x="%d" y="%d" width="12" height="12" fill="%s"/>`+"\n", width-right+15, y, col)
+		fmt.Fprintf(bw, `<text x="%d" y="%d">%s</text>`+"\n", width-right+32, y+11, html.EscapeString(s.Name))
+	}
+	fmt.Fprintln(bw, "</svg>")
+	return bw.Flush()
+}
+
+// Heatmap draws z[i][j], the value at ys[i] and xs[j], as a grid of
+// coloured cells with their values printed in them. NaN cells are grey.
+func Heatmap(w io.Writer, title, xlabel, ylabel string, xs, ys []float64, z [][]float64) error {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, row := range z {
+		for _, v := range row {
+			if !math.IsNaN(v) {
+				lo, hi = math.Min(lo, v), math.Max(hi, v)
+			}
+		}
+	}
+	cw := float64(width-left-right) / float64(max(len(xs), 1))
+	ch := float64(height-top-bottom) / float64(max(len(ys), 1))
+
+	bw := bufio.NewWriter(w)
+	header(bw, title, xlabel, ylabel)
+	for i := range ys {
+		// The first y value is at the bottom.
+		y := float64(height-bottom) - float64(i+1)*ch
+		fmt.Fprintf(bw, `<text x="%d" y="%.1f" text-anchor="end">%s</text>`+"\n", left-6, y+ch/2+4, num(ys[i]))
+		for j := range xs {
+			x := left + float64(j)*cw
+			v := z[i][j]
+			fill := "#cccccc"
+			if !math.IsNaN(v) {
+				fill = heat(v, lo, hi)
+			}
+			fmt.Fprintf(bw, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s" stroke="white"/>`+"\n", x, y, cw, ch, fill)
+			if !math.IsNaN(v) {
+				fmt.Fprintf(bw, `<text x="%.1f" y="%.1f" text-anchor="middle" font-size="11">%s</text>`+"\n", x+cw/2, y+ch/2+4, num(v))
+			}
+		}
+	}
+	for j := range xs {
+		fmt.Fprintf(bw, `<text x="%.1f" y="%d" text-anchor="middle">%s</text>`+"\n", left+(float64(j)+0.5)*cw, height-bottom+18, num(xs[j]))
+	}
+	if !math.IsInf(lo, 1) {
+		fmt.Fprintf(bw, `<rect x="%d" y="%d" width="12" height="12" fill="%s"/><text x="%d" y="%d">%s</text>`+"\n",
+			width-right+15, top, heat(hi, lo, hi), width-right+32, top+11, num(hi))
+		fmt.Fprintf(bw, `<rect x="%d" y="%d" width="12" height="12" fill="%s"/><text x="%d" y="%d">%s</text>`+"\n",
+			width-right+15, top+20, heat(lo, lo, hi), width-right+32, top+31, num(lo))
+	}
+	fmt.Fprintln(bw, "</svg>")
+	return bw.Flush()
+}
+
+func header(w io.Writer, title, xlabel, ylabel string) {
+	fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="sans-serif" font-size="12">`+"\n", width, height)
+	fmt.Fprintf(w, `<rect width="%d" height="%d" fill="white"/>`+"\n", width, height)
+	fmt.Fprintf(w, `<text x="%d" y="22" font-size="15" text-anchor="middle">%s</text>`+"\n", (width-right+left)/2, html.EscapeString(title))
+	fmt.Fprintf(w, `<text x="%d" y="%d" text-anchor="middle">%s</text>`+"\n", (width-right+left)/2, height-15, html.EscapeString(xlabel))
+	fmt.Fprintf(w, `<text x="18" y="%d" text-anchor="middle" transform="rotate(-90 18 %d)">%s</text>`+"\n",
+		(height-bottom+top)/2, (height-bottom+top)/2, html.EscapeString(ylabel))
+}
+
+// axes draws the frame with five ticks on each axis.
+func axes(w io.Writer, xlo, xhi, ylo, yhi float64, px, py func(float64) float64) {
+	fmt.Fprintf(w, `<rect x="%d" y="%d" width="%d" height="%d" fill="none" stroke="black"/>`+"\n",
+		left, top, width-left-right, height-top-bottom)
+	for i := 0; i <= 4; i++ {
+		x := xlo + (xhi-xlo)*float64(i)/4
+		y := ylo + (yhi-ylo)*float64(i)/4
+		fmt.Fprintf(w, `<text x="%.1f" y="%d" text-anchor="middle">%s</text>`+"\n", px(x), height-bottom+18, num(x))
+		fmt.Fprintf(w, `<text x="%d" y="%.1f" text-anchor="end">%s</text>`+"\n", left-6, py(y)+4, num(y))
+		fmt.Fprintf(w, `<line x1="%d" x2="%d" y1="%.1f" y2="%.1f" stroke="#eeeeee"/>`+"\n", left, width-right, py(y), py(y))
+	}
+}
+
+// pad widens a degenerate or tight range by 5% on each side.
+func pad(lo, hi float64) (float64, float64) {
+	if hi == lo {
+		return lo - 1, hi + 1
+	}
+	d := (hi - lo) * 0.05
+	return lo - d, hi + d
+}
+
+// heat maps v in [lo,hi] from light yellow to dark red.
+func heat(v, lo, hi float64) string {
+	f := 0.5
+	if hi > lo {
+		f = (v - lo) / (hi - lo)
+	}
+	r := 255 - int(75*f)
+	g := 245 - int(215*f)
+	b := 200 - int(170*f)
+	return fmt.Sprintf("#%02x%02x%02x", r, g, b)
+}
+
+func num(v float64) string {
+	return fmt.Sprintf("%.4g", v)
+}
@@ -0,0 +1,49 @@
+// Command rsabenders solves a data.dat instance by Benders decomposition
+// between routing and spectrum assignment and prints the iterations and
+// the plan.
+//
+//	rsabenders -data data.dat -time 1m
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/dilwar-crnlab/hpsr_2025/benders"
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("rsabenders: ")
+	data := flag.String("data", "data.dat", "GMPL data file")
+	var opt benders.Options
+	flag.IntVar(&opt.Budget, "budget", 100000, "nodes per master solve and per subproblem, 0 for no limit")
+	limit := flag.Duration("time", time.Minute, "time limit, 0 for none")
+	quiet := flag.Bool("q", false, "do not print the iterations")
+	flag.Parse()
+
+	in, err := instance.Load(*data)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *limit > 0 {
+		opt.Deadline = time.Now().Add(*limit)
+	}
+	if !*quiet {
+		opt.Log = os.Stdout
+	}
+	t0 := time.Now()
+	r := benders.Solve(in, opt)
+	fmt.Printf("%d iterations, %d cuts, %d master nodes in %v; bound %g, optimal %v\n",
+		r.Iterations, r.Cuts, r.Nodes, time.Since(t0).Round(time.Millisecond), r.Bound, r.Optimal)
+	if err := r.Plan.Validate(in); err != nil {
+		log.Fatal(err)
+	}
+	if err := r.Plan.Write(os.Stdout, in); err != nil {
+		log.Fatal(err)
+	}
+}
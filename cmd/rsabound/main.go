@@ -0,0 +1,100 @@
+// Command rsabound computes fast lower bounds on the spectrum needed to
+// accept every request of a data.dat instance and compares them with the
+// S_max of first-fit-decreasing plans and, when given, of an ILP solution.
+// The shortest path bounds only hold for plans that route every request
+// on its shortest path, so their gap is only reported for such plans.
+//
+//	rsabound -data data.dat -smax 12
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/dilwar-crnlab/hpsr_2025/bounds"
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+	"github.com/dilwar-crnlab/hpsr_2025/plan"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("rsabound: ")
+	data := flag.String("data", "data.dat", "GMPL data file")
+	smax := flag.Int("smax", 0, "S_max of an ILP solution to compare against")
+	budget := flag.Int("budget", 1000000, "branch nodes of the exact clique search")
+	flag.Parse()
+
+	in, err := instance.Load(*data)
+	if err != nil {
+		log.Fatal(err)
+	}
+	rt := plan.Shortest(in)
+	avg := bounds.AverageLoad(in)
+	fixed, fixedLink := bounds.LinkLoad(in)
+	sp, spLink := bounds.RoutedLoad(in, rt)
+	cl, clique, exact := bounds.Clique(in, rt, *budget)
+	lo, _ := bounds.Disjoint(in)
+
+	// The heuristic gets all the spectrum it needs, so its S_max measures
+	// the spectrum required to accept every routable request.
+	_, hi := bounds.Disjoint(in)
+	ffd := plan.FirstFitDecreasing(in, hi)
+	ffdSP := plan.FirstFitDecreasingOn(in, rt, hi)
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "bound\tslots\tnote")
+	fmt.Fprintf(tw, "average link load\t%d\tany routing\n", avg)
+	if fixed > 0 {
+		fmt.Fprintf(tw, "unavoidable link load\t%d\tany routing, link %v\n", fixed, fixedLink)
+	} else {
+		fmt.Fprintf(tw, "unavoidable link load\t0\tany routing, no link is on every route of a request\n")
+	}
+	fmt.Fprintf(tw, "max link load\t%d\tshortest path, cheapest modulation, link %v\n", sp, spLink)
+	note := "shortest path routing"
+	if !exact {
+		note += ", search truncated"
+	}
+	fmt.Fprintf(tw, "conflict clique\t%d\t%s, %d requests\n", cl, note, len(clique))
+	fmt.Fprintf(tw, "ilp.mod disjointness\t%d\tall accepted blocks pairwise disjoint\n", lo)
+	fmt.Fprintln(tw)
+
+	best := max(avg, fixed)
+	fmt.Fprintln(tw, "solution\tS_max\tgap to routing-free bound\tgap to shortest path bound")
+	row := func(name string, s int, shortest bool) {
+		fmt.Fprintf(tw, "%s\t%d\t%.1f%%\t", name, s, 100*bounds.Gap(s, best))
+		if shortest {
+			fmt.Fprintf(tw, "%.1f%%\n", 100*bounds.Gap(s, max(sp, cl)))
+		} else {
+			fmt.Fprintln(tw, "-")
+		}
+	}
+	ffdName := func(name string, p plan.Plan) string {
+		return fmt.Sprintf("%s (%d/%d accepted)", name, len(p), len(in.Traffic))
+	}
+	row(ffdName("first-fit decreasing", ffd), ffd.SMax(), onRoutes(ffd, rt))
+	row(ffdName("first-fit decreasing, shortest paths", ffdSP), ffdSP.SMax(), len(ffdSP) == len(rt))
+	if *smax > 0 {
+		// The routes of the ILP solution are unknown.
+		row("ILP", *smax, false)
+	}
+	if err := tw.Flush(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// onRoutes reports whether p accepts every request of rt on its route in
+// rt, the plans the shortest path bounds hold for.
+func onRoutes(p plan.Plan, rt map[instance.Demand]plan.Route) bool {
+	if len(p) != len(rt) {
+		return false
+	}
+	for _, a := range p {
+		if r, ok := rt[a.T]; !ok || r != a.Route {
+			return false
+		}
+	}
+	return true
+}
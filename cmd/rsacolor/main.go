@@ -0,0 +1,58 @@
+// Command rsacolor fixes the routing of a data.dat instance and assigns
+// spectrum on the conflict graph of the routed requests.
+//
+//	rsacolor -data data.dat -algo exact
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/dilwar-crnlab/hpsr_2025/conflict"
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+	"github.com/dilwar-crnlab/hpsr_2025/plan"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("rsacolor: ")
+	data := flag.String("data", "data.dat", "GMPL data file")
+	algo := flag.String("algo", "dsatur", "spectrum assignment: dsatur, exact")
+	budget := flag.Int("budget", 1000000, "branch nodes of the exact search")
+	flag.Parse()
+
+	in, err := instance.Load(*data)
+	if err != nil {
+		log.Fatal(err)
+	}
+	g := conflict.Build(in, plan.Shortest(in))
+	fmt.Printf("conflict graph: %d requests, %d conflicts\n", g.Len(), g.Edges())
+	var p plan.Plan
+	switch *algo {
+	case "dsatur":
+		p = g.DSatur()
+	case "exact":
+		var found, optimal bool
+		p, found, optimal = g.Exact(*budget)
+		switch {
+		case !found:
+			fmt.Println("no assignment of every request fits in the zones; falling back to DSatur")
+			p = g.DSatur()
+		case optimal:
+			fmt.Println("optimal S_max")
+		default:
+			fmt.Println("node budget exhausted; S_max not proven optimal")
+		}
+	default:
+		log.Fatalf("unknown algorithm %q", *algo)
+	}
+	p.Sort(in)
+	if err := p.Validate(in); err != nil {
+		log.Fatal(err)
+	}
+	if err := p.Write(os.Stdout, in); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,62 @@
+// Command rsamarkov computes the exact blocking of allocation policies on a
+// tiny data.dat instance from the Markov chain of its spectrum states and,
+// with -reps, compares it with simulation.
+//
+//	rsamarkov -data data.dat -slots 30 -load 4
+//	rsamarkov -data data_classes.dat -slots 14 -load 3 -reps 10
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+	"github.com/dilwar-crnlab/hpsr_2025/markov"
+	"github.com/dilwar-crnlab/hpsr_2025/sim"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("rsamarkov: ")
+	cfg := sim.DefaultConfig()
+	data := flag.String("data", "data.dat", "GMPL data file")
+	policy := flag.String("policy", "zone-flf", "comma separated allocation policies: zone-flf, first-fit, rate-zones")
+	slots := flag.Int("slots", 0, "override N_slots, scaling the zones to it")
+	maxStates := flag.Int("max-states", 200000, "give up beyond this many states")
+	tol := flag.Float64("tol", 1e-10, "relative tolerance of the stationary probabilities")
+	sweeps := flag.Int("sweeps", 100000, "maximum Gauss-Seidel sweeps")
+	reps := flag.Int("reps", 0, "also simulate every policy in this many replications")
+	flag.Float64Var(&cfg.Load, "load", 5, "offered load in Erlang")
+	flag.Float64Var(&cfg.Holding, "holding", cfg.Holding, "mean holding time")
+	flag.IntVar(&cfg.Arrivals, "arrivals", cfg.Arrivals, "number of measured arrivals per replication")
+	flag.IntVar(&cfg.Warmup, "warmup", cfg.Warmup, "number of warm-up arrivals per replication")
+	flag.Int64Var(&cfg.Seed, "seed", cfg.Seed, "random seed of the first replication")
+	flag.Parse()
+
+	in, err := instance.Load(*data)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *slots > 0 {
+		in.ScaleZones(*slots)
+	}
+	for _, p := range strings.Split(*policy, ",") {
+		c, err := markov.Build(in, p, cfg, *maxStates)
+		if err != nil {
+			log.Fatal(err)
+		}
+		res := c.Solve(*tol, *sweeps)
+		if *reps > 0 {
+			if err := res.Simulate(in, cfg, *reps); err != nil {
+				log.Fatal(err)
+			}
+		}
+		if err := res.Write(os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println()
+	}
+}
@@ -0,0 +1,146 @@
+// Command rsamodel generates MILP formulations of a data.dat instance,
+// optionally writes them as CPLEX LP files, and compares their size, LP
+// relaxation bound and branch-and-bound performance on the same instance.
+// The spectrum each plan leaves can be measured by simulating dynamic
+// traffic from it.
+//
+//	rsamodel -data data.dat -formulation bigm,slot -per-link -zones -lp out
+//	rsamodel -data data.dat -formulation slot -gap-penalty 0.01 -sim-load 20
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+	"github.com/dilwar-crnlab/hpsr_2025/milp"
+	"github.com/dilwar-crnlab/hpsr_2025/model"
+	"github.com/dilwar-crnlab/hpsr_2025/plan"
+	"github.com/dilwar-crnlab/hpsr_2025/sim"
+	"github.com/dilwar-crnlab/hpsr_2025/spectrum"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("rsamodel: ")
+	data := flag.String("data", "data.dat", "GMPL data file")
+	forms := flag.String("formulation", "bigm,slot", "comma separated formulations: bigm, slot (only slot models -gap-penalty and -edge-reward)")
+	var opt model.Options
+	flag.BoolVar(&opt.PerLink, "per-link", true, "keep blocks apart only on shared links (false: all pairs, as ilp.mod)")
+	flag.BoolVar(&opt.Zones, "zones", true, "confine blocks to a single zone")
+	flag.Float64Var(&opt.GapPenalty, "gap-penalty", 0, "objective penalty per run of free slots on a link (slot formulation)")
+	flag.Float64Var(&opt.EdgeReward, "edge-reward", 0, "objective reward per block at a zone edge (slot formulation)")
+	simLoad := flag.Float64("sim-load", 0, "simulate dynamic traffic of this load in Erlang from each planned state, 0 for none")
+	simArrivals := flag.Int("sim-arrivals", 10000, "measured arrivals of the simulation")
+	simPolicy := flag.String("sim-policy", "zone-flf", "allocation policy of the simulation")
+	lp := flag.String("lp", "", "write each formulation to PREFIX.NAME.lp")
+	solve := flag.Bool("solve", true, "solve each formulation by branch and bound")
+	nodes := flag.Int("nodes", 100000, "branch-and-bound node limit, 0 for none")
+	limit := flag.Duration("time", time.Minute, "time limit per formulation, 0 for none")
+	flag.Parse()
+
+	in, err := instance.Load(*data)
+	if err != nil {
+		log.Fatal(err)
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "formulation\tbinary\tinteger\tcontinuous\trows\tnonzeros\tLP bound\tstatus\tobjective\tbound\tnodes\titerations\ttime\tS_max\tgaps\tfragmentation\tblocking\tvalid\t")
+	for _, name := range strings.Split(*forms, ",") {
+		gen, ok := model.Generators[name]
+		if !ok {
+			log.Fatalf("unknown formulation %q", name)
+		}
+		if err := opt.Check(name); err != nil {
+			log.Fatal(err)
+		}
+		f := gen(in, opt)
+		if *lp != "" {
+			if err := writeLP(f.MILP, *lp+"."+name+".lp"); err != nil {
+				log.Fatal(err)
+			}
+		}
+		sz := f.Size()
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\t", name, sz.Count(model.Binary), sz.Count(model.Integer),
+			sz.Count(model.Continuous), sz.Constraints(), sz.Nonzeros)
+		if !*solve {
+			fmt.Fprintln(tw, "\t\t\t\t\t\t\t\t\t\t\t\t")
+			continue
+		}
+		o := milp.Options{NodeLimit: *nodes}
+		if *limit > 0 {
+			o.Deadline = time.Now().Add(*limit)
+		}
+		t0 := time.Now()
+		res := milp.Solve(f.MILP, o)
+		el := time.Since(t0)
+		smax, gaps, frag, blocking, valid := "-", "-", "-", "-", "-"
+		if res.X != nil {
+			p := f.Decode(res.X)
+			g := p.Grid(in)
+			smax, gaps, frag = fmt.Sprint(p.SMax()), fmt.Sprint(freeRuns(g)), fmt.Sprintf("%.3f", g.Fragmentation())
+			valid = "yes"
+			if err := p.Validate(in); err != nil {
+				valid = err.Error()
+			} else if *simLoad > 0 {
+				b, err := simulate(in, p, *simPolicy, *simLoad, *simArrivals)
+				if err != nil {
+					log.Fatal(err)
+				}
+				blocking = fmt.Sprintf("%.3e", b)
+			}
+		}
+		fmt.Fprintf(tw, "%.4g\t%v\t%.4g\t%.4g\t%d\t%d\t%v\t%s\t%s\t%s\t%s\t%s\t\n", res.Root, res.Status, res.Obj, res.Bound,
+			res.Nodes, res.Iters, el.Round(time.Millisecond), smax, gaps, frag, blocking, valid)
+	}
+	if err := tw.Flush(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// freeRuns counts the runs of free slots summed over the links of g.
+func freeRuns(g *spectrum.Grid) int {
+	n := 0
+	for li := range g.Links {
+		for s := 0; s < g.Slots; s++ {
+			if g.Owner(li, s) == spectrum.Free && (s == 0 || g.Owner(li, s-1) != spectrum.Free) {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// simulate serves dynamic traffic from the state p leaves, its lightpaths
+// held for good, and returns the blocking probability.
+func simulate(in *instance.Instance, p plan.Plan, policy string, load float64, arrivals int) (float64, error) {
+	cfg := sim.DefaultConfig()
+	cfg.Load, cfg.Arrivals, cfg.Warmup = load, arrivals, arrivals/10
+	net := sim.NewNetwork(in)
+	alloc, err := sim.NewAllocator(policy, net, cfg)
+	if err != nil {
+		return 0, err
+	}
+	s := sim.New(net, alloc, cfg)
+	if err := s.Preload(p); err != nil {
+		return 0, err
+	}
+	t := s.Run().Total()
+	return t.Blocking(), nil
+}
+
+func writeLP(m *model.MILP, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := m.WriteLP(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,156 @@
+// Command rsapareto traces the Pareto front of a data.dat instance between
+// the accepted weight and the spectrum used (S_max or slot-links), exactly
+// by the epsilon-constraint method, approximately by NSGA-II, or both for
+// comparison, and exports the front as CSV and SVG.
+//
+//	rsapareto -data data.dat -objective smax -svg front.svg
+//	rsapareto -data data.dat -method epsilon,nsga -objective slotlinks -csv front.csv
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dilwar-crnlab/hpsr_2025/chart"
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+	"github.com/dilwar-crnlab/hpsr_2025/pareto"
+)
+
+type front struct {
+	method string
+	pts    []pareto.Point
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("rsapareto: ")
+	data := flag.String("data", "data.dat", "GMPL data file")
+	methods := flag.String("method", "epsilon", "comma separated methods: epsilon, nsga")
+	objective := flag.String("objective", pareto.SMax, "spectrum cost: smax or slotlinks")
+	var eo pareto.Options
+	flag.StringVar(&eo.Formulation, "formulation", "slot", "MILP formulation of the epsilon-constraint method: bigm, slot")
+	flag.IntVar(&eo.NodeLimit, "nodes", 100000, "branch-and-bound node limit per solve, 0 for none")
+	var no pareto.NSGAOptions
+	flag.IntVar(&no.Population, "pop", 60, "NSGA-II population")
+	flag.IntVar(&no.Generations, "gens", 200, "NSGA-II generations")
+	flag.Int64Var(&no.Seed, "seed", 1, "NSGA-II random seed")
+	limit := flag.Duration("time", 5*time.Minute, "time limit per method, 0 for none")
+	csvPath := flag.String("csv", "", "write the fronts to this CSV file")
+	svgPath := flag.String("svg", "", "write the fronts to this SVG file")
+	show := flag.Bool("show", false, "print the plan of every point")
+	flag.Parse()
+
+	if *objective != pareto.SMax && *objective != pareto.SlotLinks {
+		log.Fatalf("unknown objective %q", *objective)
+	}
+	in, err := instance.Load(*data)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var fronts []front
+	for _, m := range strings.Split(*methods, ",") {
+		var deadline time.Time
+		if *limit > 0 {
+			deadline = time.Now().Add(*limit)
+		}
+		t0 := time.Now()
+		var pts []pareto.Point
+		truncated := false
+		switch m {
+		case "epsilon":
+			o := eo
+			o.Objective, o.Deadline = *objective, deadline
+			if pts, truncated, err = pareto.Epsilon(in, o); err != nil {
+				log.Fatal(err)
+			}
+		case "nsga":
+			o := no
+			o.Objective, o.Deadline = *objective, deadline
+			pts = pareto.NSGA(in, o)
+		default:
+			log.Fatalf("unknown method %q", m)
+		}
+		fronts = append(fronts, front{m, pts})
+		for _, p := range pts {
+			if err := p.Plan.Validate(in); err != nil {
+				log.Fatalf("%s: %v", m, err)
+			}
+		}
+		fmt.Printf("%s: %d points in %v\n", m, len(pts), time.Since(t0).Round(time.Millisecond))
+		if truncated {
+			fmt.Printf("%s: stopped by the node or time limit, points of lower weight are missing\n", m)
+		}
+		table(os.Stdout, in, *objective, pts, *show)
+		fmt.Println()
+	}
+	if *csvPath != "" {
+		if err := writeFile(*csvPath, func(w io.Writer) error { return writeCSV(w, *objective, fronts) }); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *svgPath != "" {
+		var ss []chart.Series
+		for _, f := range fronts {
+			s := chart.Series{Name: f.method}
+			for _, p := range f.pts {
+				s.X = append(s.X, float64(p.Cost))
+				s.Y = append(s.Y, p.Weight)
+			}
+			ss = append(ss, s)
+		}
+		draw := func(w io.Writer) error {
+			return chart.Lines(w, "Pareto front of "+*data, *objective, "accepted weight", ss)
+		}
+		if err := writeFile(*svgPath, draw); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+func table(w io.Writer, in *instance.Instance, objective string, pts []pareto.Point, show bool) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "weight\taccepted\t%s\toptimal\t\n", objective)
+	for _, p := range pts {
+		fmt.Fprintf(tw, "%g\t%d\t%d\t%v\t\n", p.Weight, len(p.Plan), p.Cost, p.Optimal)
+	}
+	tw.Flush()
+	if show {
+		for _, p := range pts {
+			fmt.Fprintln(w)
+			p.Plan.Write(w, in)
+		}
+	}
+}
+
+func writeCSV(w io.Writer, objective string, fronts []front) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"method", "weight", "accepted", objective, "optimal"})
+	for _, f := range fronts {
+		for _, p := range f.pts {
+			cw.Write([]string{f.method, strconv.FormatFloat(p.Weight, 'g', -1, 64), strconv.Itoa(len(p.Plan)),
+				strconv.Itoa(p.Cost), strconv.FormatBool(p.Optimal)})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeFile(path string, to func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := to(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,116 @@
+// Command rsapool enumerates distinct optimal or near-optimal plans of a
+// data.dat instance and ranks them by secondary metrics, since plans with
+// the same TotalAccepted can leave very different spectrum behind.
+//
+//	rsapool -data data.dat -n 20 -gap 1 -sort frag,smax
+//	rsapool -data data.dat -distinct starts
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+	"github.com/dilwar-crnlab/hpsr_2025/plan"
+	"github.com/dilwar-crnlab/hpsr_2025/sat"
+)
+
+// entry is a plan of the pool with its metrics.
+type entry struct {
+	found     int
+	plan      plan.Plan
+	weight    float64
+	smax      int
+	frag      float64
+	slotLinks int
+}
+
+// keys are the ranking metrics, each better when lower.
+var keys = map[string]func(e entry) float64{
+	"weight":     func(e entry) float64 { return -e.weight },
+	"frag":       func(e entry) float64 { return e.frag },
+	"smax":       func(e entry) float64 { return float64(e.smax) },
+	"slot-links": func(e entry) float64 { return float64(e.slotLinks) },
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("rsapool: ")
+	data := flag.String("data", "data.dat", "GMPL data file")
+	n := flag.Int("n", 20, "plans to enumerate, 0 for all")
+	gap := flag.Float64("gap", 0, "accepted weight below the optimum still admitted")
+	distinct := flag.String("distinct", sat.DistinctRoutes, "what plans must differ in besides the accepted requests: routes (path or modulation), starts (also start slots), requests (nothing else)")
+	sides := flag.Bool("sides", false, "enforce the zone-FLF side order of the connection types")
+	by := flag.String("sort", "weight,frag,smax", "ranking keys: weight (higher first), frag, smax, slot-links")
+	show := flag.Int("show", 1, "print the plans of the first ranks")
+	limit := flag.Duration("time", time.Minute, "time limit, 0 for none")
+	flag.Parse()
+
+	var rank []func(entry) float64
+	for _, k := range strings.Split(*by, ",") {
+		f, ok := keys[k]
+		if !ok {
+			log.Fatalf("unknown ranking key %q", k)
+		}
+		rank = append(rank, f)
+	}
+	switch *distinct {
+	case sat.DistinctRoutes, sat.DistinctStarts, sat.DistinctRequests:
+	default:
+		log.Fatalf("unknown distinctness key %q", *distinct)
+	}
+	in, err := instance.Load(*data)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var deadline time.Time
+	if *limit > 0 {
+		deadline = time.Now().Add(*limit)
+	}
+	t0 := time.Now()
+	res := sat.Pool(sat.Encode(in, sat.Options{Sides: *sides}), *n, *gap, *distinct, deadline)
+	if res.Plans == nil {
+		log.Fatal("no optimum proven within the time limit")
+	}
+	fmt.Printf("optimal weight %g; %d plans within %g", res.Best, len(res.Plans), *gap)
+	if res.Complete {
+		fmt.Print(" (all of them)")
+	}
+	fmt.Printf(" in %v\n", time.Since(t0).Round(time.Millisecond))
+
+	es := make([]entry, len(res.Plans))
+	for i, p := range res.Plans {
+		if err := p.Validate(in); err != nil {
+			log.Fatalf("plan %d: %v", i+1, err)
+		}
+		es[i] = entry{i + 1, p, p.Weight(in), p.SMax(), p.Fragmentation(in), p.SlotLinks(in)}
+	}
+	sort.SliceStable(es, func(a, b int) bool {
+		for _, f := range rank {
+			if fa, fb := f(es[a]), f(es[b]); fa != fb {
+				return fa < fb
+			}
+		}
+		return false
+	})
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "rank\tfound\taccepted\tweight\tS_max\tfragmentation\tslot-links\t")
+	for i, e := range es {
+		fmt.Fprintf(tw, "%d\t%d\t%d\t%g\t%d\t%.3f\t%d\t\n", i+1, e.found, len(e.plan), e.weight, e.smax, e.frag, e.slotLinks)
+	}
+	if err := tw.Flush(); err != nil {
+		log.Fatal(err)
+	}
+	for i := 0; i < *show && i < len(es); i++ {
+		fmt.Printf("\nrank %d\n", i+1)
+		if err := es[i].plan.Write(os.Stdout, in); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+// Command rsarobust plans a data.dat instance for uncertain traffic with a
+// Bertsimas–Sim budget of uncertainty on T_sd, checks the plan against the
+// deviation patterns within the budget and can sweep the budget to show
+// the price of robustness.
+//
+//	rsarobust -data data.dat -gamma 1 -deviation 0.5
+//	rsarobust -data data.dat -sweep 0:4 -deviation 0.5
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+	"github.com/dilwar-crnlab/hpsr_2025/robust"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("rsarobust: ")
+	data := flag.String("data", "data.dat", "GMPL data file")
+	gamma := flag.Float64("gamma", -1, "budget of uncertainty, negative for Gamma of the data file")
+	deviation := flag.Float64("deviation", 0, "T_hat as a fraction of T_sd for requests without T_hat in the data")
+	sweep := flag.String("sweep", "", "solve for every budget LO:HI[:STEP] and print the price of robustness")
+	var opt robust.Options
+	flag.IntVar(&opt.Patterns, "check", 1000, "deviation patterns to verify the plan against, 0 for no limit")
+	flag.IntVar(&opt.NodeLimit, "nodes", 100000, "branch-and-bound node limit, 0 for none")
+	flag.IntVar(&opt.Budget, "budget", 100000, "nodes of each spectrum assignment search, 0 for no limit")
+	limit := flag.Duration("time", time.Minute, "time limit per solve, 0 for none")
+	flag.Parse()
+
+	in, err := instance.Load(*data)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, t := range in.Traffic {
+		if _, ok := in.THat[t]; !ok {
+			in.THat[t] = in.T[t].Scale(*deviation)
+		}
+	}
+	opt.Gamma = in.Gamma
+	if *gamma >= 0 {
+		opt.Gamma = *gamma
+	}
+	solve := func(g float64) robust.Result {
+		o := opt
+		o.Gamma = g
+		if *limit > 0 {
+			o.Deadline = time.Now().Add(*limit)
+		}
+		return robust.Solve(in, o)
+	}
+
+	if *sweep != "" {
+		gs, err := parseRange(*sweep)
+		if err != nil {
+			log.Fatal(err)
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprintln(tw, "Gamma\tstatus\taccepted\tweight\tassigned\tS_max\tpatterns\tfailed\tundecided\trobust\t")
+		for _, g := range gs {
+			r := solve(g)
+			c := r.Check
+			fmt.Fprintf(tw, "%g\t%v\t%d\t%g\t%d\t%d\t%d%s\t%d\t%d\t%s\t\n", g, r.Status, len(r.Routes), r.Weight,
+				len(r.Plan), r.Plan.SMax(), c.Realizations, more(c.Truncated), c.Failed, c.Undecided, yes(r.Robust))
+		}
+		if err := tw.Flush(); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	r := solve(opt.Gamma)
+	fmt.Printf("Gamma %g: %v, %d requests accepted, weight %g\n", opt.Gamma, r.Status, len(r.Routes), r.Weight)
+	if len(r.Plan) < len(r.Routes) {
+		fmt.Printf("only %d of them could be assigned spectrum for the forecast traffic\n", len(r.Plan))
+	}
+	if err := r.Plan.Validate(in); err != nil {
+		log.Fatal(err)
+	}
+	if err := r.Plan.Write(os.Stdout, in); err != nil {
+		log.Fatal(err)
+	}
+	c := r.Check
+	fmt.Printf("\n%d deviation patterns%s checked, %d not assignable, %d undecided", c.Realizations, more(c.Truncated), c.Failed, c.Undecided)
+	if c.Worst != nil {
+		fmt.Printf(" (first: %v deviating)", c.Worst)
+	}
+	fmt.Println()
+	if !r.Robust {
+		fmt.Println("the plan is not proven robust for this budget")
+	}
+}
+
+func yes(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+func more(truncated bool) string {
+	if truncated {
+		return "+"
+	}
+	return ""
+}
+
+func parseRange(s string) ([]float64, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return nil, fmt.Errorf("range %q: want LO:HI[:STEP]", s)
+	}
+	nums := []float64{0, 0, 1}
+	for i, p := range parts {
+		v, err := strconv.ParseFloat(p, 64)
+		if err != nil {
+			return nil, fmt.Errorf("range %q: %w", s, err)
+		}
+		nums[i] = v
+	}
+	if nums[2] <= 0 {
+		return nil, fmt.Errorf("range %q: step must be positive", s)
+	}
+	var vs []float64
+	for i := 0; nums[0]+float64(i)*nums[2] <= nums[1]+1e-9; i++ {
+		vs = append(vs, nums[0]+float64(i)*nums[2])
+	}
+	return vs, nil
+}
@@ -0,0 +1,99 @@
+// Command rsasat encodes the acceptance problem of a data.dat instance in
+// CNF, writes it in DIMACS CNF or WCNF format for external SAT and MaxSAT
+// solvers, and solves it with the built-in CDCL solver.
+//
+//	rsasat -data data.dat -all -cnf out.cnf
+//	rsasat -data data.dat -wcnf out.wcnf -sides
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+	"github.com/dilwar-crnlab/hpsr_2025/sat"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("rsasat: ")
+	data := flag.String("data", "data.dat", "GMPL data file")
+	var opt sat.Options
+	flag.BoolVar(&opt.All, "all", false, "require every routable request (SAT); otherwise maximise the accepted weight (MaxSAT)")
+	flag.BoolVar(&opt.Sides, "sides", false, "enforce the zone-FLF side order of the connection types")
+	cnf := flag.String("cnf", "", "write the hard clauses in DIMACS CNF format to this file")
+	wcnf := flag.String("wcnf", "", "write hard and soft clauses in DIMACS WCNF format to this file")
+	solve := flag.Bool("solve", true, "solve with the built-in solver")
+	limit := flag.Duration("time", time.Minute, "time limit, 0 for none")
+	flag.Parse()
+
+	in, err := instance.Load(*data)
+	if err != nil {
+		log.Fatal(err)
+	}
+	e := sat.Encode(in, opt)
+	fmt.Printf("encoding: %d variables, %d hard clauses, %d soft clauses\n", e.Vars, len(e.Clauses), len(e.Soft))
+	if *cnf != "" {
+		if err := write(*cnf, e.WriteDIMACS); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *wcnf != "" {
+		if err := write(*wcnf, e.WriteWCNF); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if !*solve {
+		return
+	}
+	var deadline time.Time
+	if *limit > 0 {
+		deadline = time.Now().Add(*limit)
+	}
+	t0 := time.Now()
+	var model []bool
+	if opt.All {
+		s := sat.NewSolver(e.CNF)
+		s.Deadline = deadline
+		st := s.Solve()
+		fmt.Printf("%v after %d conflicts in %v\n", st, s.Conflicts, time.Since(t0).Round(time.Millisecond))
+		if st != sat.Satisfiable {
+			return
+		}
+		model = s.Model()
+	} else {
+		r := sat.SolveMax(e.CNF, deadline)
+		fmt.Printf("%v: accepted weight %d", r.Status, r.Weight)
+		if r.Optimal {
+			fmt.Print(" (optimal)")
+		}
+		fmt.Printf(" after %d SAT calls, %d conflicts in %v\n", r.Calls, r.Conflicts, time.Since(t0).Round(time.Millisecond))
+		if r.Status != sat.Satisfiable {
+			return
+		}
+		model = r.Model
+	}
+	p := e.Decode(model)
+	if err := p.Validate(in); err != nil {
+		log.Fatal(err)
+	}
+	if err := p.Write(os.Stdout, in); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func write(path string, to func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := to(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,197 @@
+// Command rsasim simulates dynamic provisioning on a data.dat instance.
+//
+//	rsasim -data data.dat -policy zone-flf -load 50 -arrivals 100000
+//	rsasim -data data.dat -load 50 -batch 0.05
+//	rsasim -data data.dat -policy zone-flf,first-fit -reps 20 -antithetic
+//	rsasim -data data.dat -load 20 -levels 60:95:5 -split 3 -reps 10 -validate
+//	rsasim -data data.dat -load 50 -fixed-point -reps 10
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+	"github.com/dilwar-crnlab/hpsr_2025/sim"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("rsasim: ")
+	cfg := sim.DefaultConfig()
+	data := flag.String("data", "data.dat", "GMPL data file")
+	policy := flag.String("policy", "zone-flf", "comma separated allocation policies: zone-flf, first-fit, time-aware, rate-zones")
+	preempt := flag.String("preempt", "", "preemption as BY:VICTIM classes, e.g. gold:bronze")
+	reserve := flag.String("reserve", "", "admission thresholds per zone as ZONE=SLOTS,..., with zones rate<N_req> under rate-zones")
+	reserveClasses := flag.String("reserve-classes", "", "comma separated classes subject to admission control (default all but the highest priority)")
+	crankback := flag.String("crankback", "", "comma separated retry steps: path, mod, zone, side, split, defrag")
+	sweep := flag.String("sweep", "", "tune admission thresholds over LO:HI:STEP slots")
+	batch := flag.Float64("batch", 0, "buffer arrivals for windows of this many time units and place each batch with the ILP, 0 for online allocation")
+	batchNodes := flag.Int("batch-nodes", 10000, "branch-and-bound node limit per batch, 0 for none")
+	batchTime := flag.Duration("batch-time", 0, "wall-clock time limit per batch, 0 for none (runs then depend on machine speed)")
+	flag.Float64Var(&cfg.Load, "load", cfg.Load, "offered load in Erlang")
+	flag.Float64Var(&cfg.Holding, "holding", cfg.Holding, "mean holding time")
+	flag.IntVar(&cfg.Arrivals, "arrivals", cfg.Arrivals, "number of measured arrivals")
+	flag.IntVar(&cfg.Warmup, "warmup", cfg.Warmup, "number of warm-up arrivals")
+	flag.Int64Var(&cfg.Seed, "seed", cfg.Seed, "random seed")
+	flag.BoolVar(&cfg.Antithetic, "antithetic", false, "draw the antithetic traffic of the seed; with -reps, pair every replication with its antithetic run")
+	reps := flag.Int("reps", 0, "compare the policies over this many replications with common random numbers and paired confidence intervals")
+	levels := flag.String("levels", "", "estimate rare blocking by splitting at these thresholds of the busiest link's occupied slots, LO:HI[:STEP]")
+	split := flag.Int("split", 3, "copies per threshold crossing with -levels")
+	validate := flag.Bool("validate", false, "with -levels, also estimate the blocking by direct simulation")
+	fixedPoint := flag.Bool("fixed-point", false, "approximate the blocking per demand with the reduced-load fixed point; with -reps, compare it with simulation")
+	flag.Parse()
+
+	in, err := instance.Load(*data)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *preempt != "" {
+		by, victim, ok := strings.Cut(*preempt, ":")
+		if !ok {
+			log.Fatalf("-preempt %q: want BY:VICTIM", *preempt)
+		}
+		cfg.Preempt = &sim.Preemption{By: by, Victim: victim}
+	}
+	if cfg.Crankback, err = sim.ParseCrankback(*crankback); err != nil {
+		log.Fatal(err)
+	}
+	if *batch > 0 {
+		cfg.Batch = &sim.Batching{Window: *batch, NodeLimit: *batchNodes, TimeLimit: *batchTime}
+	}
+	if *reserve != "" || *sweep != "" {
+		adm := sim.NewAdmission(in)
+		if *reserveClasses != "" {
+			adm.Classes = map[string]bool{}
+			for _, c := range strings.Split(*reserveClasses, ",") {
+				adm.Classes[c] = true
+			}
+		}
+		if adm.Threshold, err = sim.ParseThresholds(*reserve); err != nil {
+			log.Fatal(err)
+		}
+		cfg.Admission = adm
+	}
+	if *sweep != "" {
+		values, err := parseRange(*sweep)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("thresholds\tweighted loss")
+		st, err := sim.SweepReservation(in, *policy, cfg, cfg.Admission, values, os.Stdout)
+		if err != nil {
+			log.Fatal(err)
+		}
+		zones, err := sim.ZoneNames(in, *policy, cfg)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("\nbest thresholds %s\n", sim.FormatThresholds(cfg.Admission.Threshold, zones))
+		if err := st.Write(os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if *levels != "" {
+		sp := sim.Splitting{Factor: *split, Replications: *reps}
+		if sp.Replications == 0 {
+			sp.Replications = 10
+		}
+		if sp.Thresholds, err = parseRange(*levels); err != nil {
+			log.Fatal(err)
+		}
+		for _, p := range strings.Split(*policy, ",") {
+			res, err := sim.Rare(in, p, cfg, sp)
+			if err != nil {
+				log.Fatal(err)
+			}
+			fmt.Printf("policy %s, splitting\n", p)
+			if err := res.Write(os.Stdout); err != nil {
+				log.Fatal(err)
+			}
+			if *validate {
+				e, err := sim.Direct(in, p, cfg, sp.Replications)
+				if err != nil {
+					log.Fatal(err)
+				}
+				fmt.Printf("policy %s, direct\nblocking %v, %d arrivals simulated\n", p, e, sp.Replications*(cfg.Warmup+cfg.Arrivals))
+			}
+			fmt.Println()
+		}
+		return
+	}
+	if *fixedPoint {
+		for _, p := range strings.Split(*policy, ",") {
+			a, err := sim.DefaultFixedPoint().Solve(sim.NewNetwork(in), p, cfg.Load)
+			if err != nil {
+				log.Fatal(err)
+			}
+			if *reps > 0 {
+				if err := a.Simulate(in, cfg, *reps); err != nil {
+					log.Fatal(err)
+				}
+			}
+			if err := a.Write(os.Stdout); err != nil {
+				log.Fatal(err)
+			}
+			fmt.Println()
+		}
+		return
+	}
+	if *reps > 0 {
+		cmp, err := sim.Compare(in, strings.Split(*policy, ","), cfg, *reps, cfg.Antithetic)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := cmp.Write(os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	// Every policy sees the same arrivals, as all runs share the seed.
+	for _, p := range strings.Split(*policy, ",") {
+		net := sim.NewNetwork(in)
+		alloc, err := sim.NewAllocator(p, net, cfg)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if rz, ok := alloc.(*sim.RateZones); ok {
+			if err := rz.WriteLayout(os.Stdout); err != nil {
+				log.Fatal(err)
+			}
+		}
+		st := sim.New(net, alloc, cfg).Run()
+		if err := st.Write(os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println()
+	}
+}
+
+// parseRange expands LO:HI:STEP (STEP defaults to 1) into its values.
+func parseRange(s string) ([]int, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return nil, fmt.Errorf("range %q: want LO:HI[:STEP]", s)
+	}
+	nums := []int{0, 0, 1}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return nil, fmt.Errorf("range %q: %w", s, err)
+		}
+		nums[i] = n
+	}
+	if nums[2] <= 0 {
+		return nil, fmt.Errorf("range %q: step must be positive", s)
+	}
+	var vs []int
+	for v := nums[0]; v <= nums[1]; v += nums[2] {
+		vs = append(vs, v)
+	}
+	return vs, nil
+}
@@ -0,0 +1,56 @@
+// Command rsasolve solves a data.dat instance with one or more engines and
+// compares their plans.
+//
+//	rsasolve -data data.dat -engine cp,ffd,dsatur
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+	"github.com/dilwar-crnlab/hpsr_2025/solve"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("rsasolve: ")
+	data := flag.String("data", "data.dat", "GMPL data file")
+	engine := flag.String("engine", "cp", "comma separated engines: "+strings.Join(solve.Names(), ", "))
+	verbose := flag.Bool("v", false, "print every plan")
+	var opt solve.Options
+	flag.IntVar(&opt.Budget, "budget", 1000000, "search nodes per engine, 0 for no limit")
+	flag.DurationVar(&opt.TimeLimit, "time", 0, "time limit per engine, 0 for none")
+	flag.Parse()
+
+	in, err := instance.Load(*data)
+	if err != nil {
+		log.Fatal(err)
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "engine\taccepted\tweight\tS_max\tslot-links\toptimal\tnodes\ttime\tvalid\t")
+	for _, name := range strings.Split(*engine, ",") {
+		res, err := solve.Run(name, in, opt)
+		if err != nil {
+			log.Fatal(err)
+		}
+		valid := "yes"
+		if err := res.Plan.Validate(in); err != nil {
+			valid = err.Error()
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%g\t%d\t%d\t%v\t%d\t%v\t%s\t\n", name, len(res.Plan), res.Plan.Weight(in),
+			res.Plan.SMax(), res.Plan.SlotLinks(in), res.Optimal, res.Nodes, res.Elapsed.Round(1000), valid)
+		if *verbose {
+			tw.Flush()
+			res.Plan.Write(os.Stdout, in)
+			fmt.Println()
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,94 @@
+// Command rsastat prints statistics of a data.dat instance and estimates
+// the size of the model ilp.mod generates for it, warning when glpsol is
+// unlikely to cope.
+//
+//	rsastat -data data.dat
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/dilwar-crnlab/hpsr_2025/bounds"
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+	"github.com/dilwar-crnlab/hpsr_2025/model"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("rsastat: ")
+	data := flag.String("data", "data.dat", "GMPL data file")
+	maxVars := flag.Int("max-vars", 50000, "warn above this many variables")
+	maxInts := flag.Int("max-ints", 5000, "warn above this many binary and integer variables")
+	maxCons := flag.Int("max-cons", 100000, "warn above this many constraints")
+	flag.Parse()
+
+	in, err := instance.Load(*data)
+	if err != nil {
+		log.Fatal(err)
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	all, reachable := model.Combos(in)
+	fmt.Fprintf(tw, "nodes\t%d\n", len(in.Nodes))
+	fmt.Fprintf(tw, "links\t%d\n", len(in.Links))
+	fmt.Fprintf(tw, "|TRAFFIC|\t%d\n", len(in.Traffic))
+	fmt.Fprintf(tw, "(t,p,m) combinations\t%d (%d within reach)\n", all, reachable)
+	fmt.Fprintf(tw, "y[t1,t2] pairs\t%d\n", model.Pairs(in))
+	fmt.Fprintf(tw, "N_slots\t%d\n", in.NSlots)
+	fmt.Fprintln(tw)
+
+	size := model.BigMSize(in)
+	fmt.Fprintln(tw, "variables\tkind\tcount")
+	for _, f := range size.Vars {
+		fmt.Fprintf(tw, "%s\t%s\t%d\n", f.Name, f.Kind, f.Count)
+	}
+	fmt.Fprintf(tw, "total\t\t%d (%d binary, %d integer)\n", size.Variables(), size.Count(model.Binary), size.Count(model.Integer))
+	fmt.Fprintln(tw)
+	fmt.Fprintln(tw, "constraints\t\tcount")
+	for _, f := range size.Cons {
+		fmt.Fprintf(tw, "%s\t\t%d\n", f.Name, f.Count)
+	}
+	fmt.Fprintf(tw, "total\t\t%d\n", size.Constraints())
+	fmt.Fprintln(tw)
+
+	lo, hi := bounds.Disjoint(in)
+	ll, link := bounds.LinkLoad(in)
+	fmt.Fprintf(tw, "S_max lower bound, ilp.mod disjointness\t%d\n", lo)
+	fmt.Fprintf(tw, "S_max upper bound, ilp.mod disjointness\t%d\n", hi)
+	if ll > 0 {
+		fmt.Fprintf(tw, "S_max lower bound, link load\t%d on %v\n", ll, link)
+	} else {
+		fmt.Fprintf(tw, "S_max lower bound, link load\t0 (no link is on every option of a request)\n")
+	}
+	if err := tw.Flush(); err != nil {
+		log.Fatal(err)
+	}
+
+	var warn []string
+	if un := bounds.Unroutable(in); len(un) > 0 {
+		warn = append(warn, fmt.Sprintf("%d request(s) have no modulation within reach and can never be accepted, e.g. %v", len(un), un[0]))
+	}
+	if lo > in.NSlots {
+		warn = append(warn, fmt.Sprintf("N_slots = %d is below the lower bound %d: not every request can be accepted", in.NSlots, lo))
+	}
+	if n := size.Variables(); n > *maxVars {
+		warn = append(warn, fmt.Sprintf("%d variables exceed %d", n, *maxVars))
+	}
+	if n := size.Count(model.Binary) + size.Count(model.Integer); n > *maxInts {
+		warn = append(warn, fmt.Sprintf("%d binary/integer variables exceed %d; the y[t1,t2] disjunctions dominate with %d", n, *maxInts, model.Pairs(in)))
+	}
+	if n := size.Constraints(); n > *maxCons {
+		warn = append(warn, fmt.Sprintf("%d constraints exceed %d", n, *maxCons))
+	}
+	if in.MBig == 0 {
+		warn = append(warn, "M_big is not set; ilp.mod requires it")
+	} else if in.MBig < hi+1 {
+		warn = append(warn, fmt.Sprintf("M_big = %d is below the upper bound %d on S_max + 1 and may cut off solutions", in.MBig, hi+1))
+	}
+	for _, w := range warn {
+		fmt.Println("warning:", w)
+	}
+}
@@ -0,0 +1,126 @@
+// Command rsastoch plans a data.dat instance for traffic given as
+// scenarios (SCENARIOS, Prob, T_scen) with the two-stage stochastic
+// program of package stochastic, or runs a sample-average approximation
+// that samples the scenarios, or T_sd within T_hat when there are none.
+//
+//	rsastoch -data scenarios.dat
+//	rsastoch -data data.dat -saa -deviation 0.3 -n 10 -reps 5 -eval 200
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+	"github.com/dilwar-crnlab/hpsr_2025/stochastic"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("rsastoch: ")
+	data := flag.String("data", "data.dat", "GMPL data file")
+	saa := flag.Bool("saa", false, "run a sample-average approximation instead of solving the scenarios of the data")
+	var opt stochastic.SAAOptions
+	flag.IntVar(&opt.Samples, "n", 10, "scenarios per SAA replication")
+	flag.IntVar(&opt.Replications, "reps", 5, "SAA replications")
+	flag.IntVar(&opt.Eval, "eval", 200, "scenarios of the SAA out-of-sample evaluation")
+	flag.Int64Var(&opt.Seed, "seed", 1, "random seed of the SAA")
+	deviation := flag.Float64("deviation", 0, "T_hat as a fraction of T_sd for requests without T_hat in the data")
+	flag.IntVar(&opt.NodeLimit, "nodes", 100000, "branch-and-bound node limit per solve, 0 for none")
+	limit := flag.Duration("time", time.Minute, "time limit of the run, 0 for none")
+	verbose := flag.Bool("v", false, "print the plan of every scenario")
+	flag.Parse()
+
+	in, err := instance.Load(*data)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, t := range in.Traffic {
+		if _, ok := in.THat[t]; !ok {
+			in.THat[t] = in.T[t].Scale(*deviation)
+		}
+	}
+	if *limit > 0 {
+		opt.Deadline = time.Now().Add(*limit)
+	}
+
+	if *saa {
+		if opt.Samples < 1 || opt.Replications < 1 || opt.Eval < 1 {
+			log.Fatal("-n, -reps and -eval must be positive")
+		}
+		r := stochastic.SAA(in, opt)
+		if len(r.Candidates) == 0 {
+			log.Fatal("no replication found a solution")
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprintln(tw, "replication\treserved\tin-sample\tout-of-sample\t95% CI\t")
+		for i, c := range r.Candidates {
+			best := ""
+			if i == r.Best {
+				best = " *"
+			}
+			fmt.Fprintf(tw, "%d%s\t%d\t%.4g\t%.4g\t±%.3g\t\n", i+1, best, len(c.First), c.InSample,
+				c.OutOfSample.Mean, c.OutOfSample.HalfWidth)
+		}
+		if err := tw.Flush(); err != nil {
+			log.Fatal(err)
+		}
+		lo, hi := r.Lower(), r.Upper
+		fmt.Printf("\nexpected weight: lower bound %.4g ± %.3g (%d scenarios), upper bound %.4g ± %.3g (%d replications)\n",
+			lo.Mean, lo.HalfWidth, lo.N, hi.Mean, hi.HalfWidth, hi.N)
+		fmt.Printf("optimality gap estimate %.4g\n\n", hi.Mean-lo.Mean)
+		first(r.Candidates[r.Best].First)
+		return
+	}
+
+	scens := stochastic.Scenarios(in)
+	r := stochastic.Solve(in, scens, opt.Options)
+	if r.First == nil {
+		log.Fatalf("%v, no solution", r.Status)
+	}
+	fmt.Printf("%v, expected weight %.4g (bound %.4g), after layout %.4g\n\n", r.Status, r.Expected, r.Bound, r.Realized)
+	first(r.First)
+	fmt.Println()
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "scenario\tprob\taccepted\tweight\tS_max\t")
+	for i, s := range r.Scenarios {
+		p := r.Plans[i]
+		fmt.Fprintf(tw, "%s\t%.4g\t%d\t%g\t%d\t\n", s.Name, s.Prob, len(p), p.Weight(in), p.SMax())
+	}
+	if err := tw.Flush(); err != nil {
+		log.Fatal(err)
+	}
+	if *verbose {
+		eff := in.Efficiency()
+		for i, s := range r.Scenarios {
+			si := s.Instance(in, eff)
+			if err := r.Plans[i].Validate(si); err != nil {
+				log.Fatalf("scenario %s: %v", s.Name, err)
+			}
+			fmt.Printf("\nscenario %s\n", s.Name)
+			if err := r.Plans[i].Write(os.Stdout, si); err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+}
+
+// first prints the first-stage reservations.
+func first(f map[instance.Demand]stochastic.Choice) {
+	ts := make([]instance.Demand, 0, len(f))
+	for t := range f {
+		ts = append(ts, t)
+	}
+	sort.Slice(ts, func(a, b int) bool { return ts[a].String() < ts[b].String() })
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "request\tpath\tzone\t")
+	for _, t := range ts {
+		fmt.Fprintf(tw, "%v\t%s\t%s\t\n", t, f[t].P, f[t].Zone)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,211 @@
+// Command rsasweep varies one or two parameters of a data.dat instance,
+// re-derives the data depending on them, re-solves at every point and
+// prints the response curve or heatmap, optionally also as CSV and SVG.
+//
+//	rsasweep -data data.dat -x G=0:4 -engine cp -svg g.svg
+//	rsasweep -data data.dat -x K=1:3 -y N_slots=20:60:10 -metric weight
+//	rsasweep -data data.dat -x R[m1]=300km:0.6Mm:100km
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/dilwar-crnlab/hpsr_2025/chart"
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+	"github.com/dilwar-crnlab/hpsr_2025/sensitivity"
+	"github.com/dilwar-crnlab/hpsr_2025/solve"
+)
+
+// metrics are the values a point can be reported by.
+var metrics = map[string]func(p sensitivity.Point) float64{
+	"accepted": func(p sensitivity.Point) float64 { return float64(len(p.Plan)) },
+	"weight":   func(p sensitivity.Point) float64 { return p.Weight },
+	"smax":     func(p sensitivity.Point) float64 { return float64(p.Plan.SMax()) },
+	"frag":     func(p sensitivity.Point) float64 { return p.Fragmentation },
+	"routable": func(p sensitivity.Point) float64 { return float64(p.Routable) },
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("rsasweep: ")
+	data := flag.String("data", "data.dat", "GMPL data file")
+	engine := flag.String("engine", "cp", "solve engine: "+strings.Join(solve.Names(), ", "))
+	xs := flag.String("x", "G=0:3", "swept parameter NAME=LO:HI[:STEP] or NAME=V1,V2,...; NAME is N_slots, G, K, R (reach factor) or R[m]")
+	ys := flag.String("y", "", "second swept parameter, for a heatmap")
+	metric := flag.String("metric", "weight", "metric of the chart and heatmap: accepted, weight, smax, frag, routable")
+	csvPath := flag.String("csv", "", "write every point to this CSV file")
+	svgPath := flag.String("svg", "", "write the response curve or heatmap to this SVG file")
+	var opt solve.Options
+	flag.IntVar(&opt.Budget, "budget", 1000000, "search nodes per point, 0 for no limit")
+	flag.DurationVar(&opt.TimeLimit, "time", 0, "time limit per point, 0 for none")
+	flag.Parse()
+
+	value, ok := metrics[*metric]
+	if !ok {
+		log.Fatalf("unknown metric %q", *metric)
+	}
+	x, err := sensitivity.ParseParam(*xs)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var y *sensitivity.Param
+	if *ys != "" {
+		p, err := sensitivity.ParseParam(*ys)
+		if err != nil {
+			log.Fatal(err)
+		}
+		y = &p
+	}
+	in, err := instance.Load(*data)
+	if err != nil {
+		log.Fatal(err)
+	}
+	pts := sensitivity.Sweep(in, *engine, opt, x, y)
+	for _, p := range pts {
+		if p.Err != nil {
+			log.Printf("%s=%g %s: %v", x.Name, p.X, yLabel(y, p.Y), p.Err)
+		}
+	}
+
+	if y == nil {
+		curve(os.Stdout, x, pts)
+	} else {
+		grid(os.Stdout, *metric, x, *y, pts, value)
+	}
+	if *csvPath != "" {
+		if err := writeFile(*csvPath, func(w io.Writer) error { return writeCSV(w, x, y, pts) }); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *svgPath != "" {
+		title := fmt.Sprintf("%s by %s (%s)", *metric, x.Name, *engine)
+		draw := func(w io.Writer) error {
+			s := chart.Series{Name: *metric}
+			for _, p := range pts {
+				s.X = append(s.X, p.X)
+				s.Y = append(s.Y, valueOf(p, value))
+			}
+			return chart.Lines(w, title, x.Name, *metric, []chart.Series{s})
+		}
+		if y != nil {
+			title = fmt.Sprintf("%s by %s and %s (%s)", *metric, x.Name, y.Name, *engine)
+			draw = func(w io.Writer) error {
+				return chart.Heatmap(w, title, x.Name, y.Name, x.Values, y.Values, matrix(x, *y, pts, value))
+			}
+		}
+		if err := writeFile(*svgPath, draw); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+func yLabel(y *sensitivity.Param, v float64) string {
+	if y == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s=%g", y.Name, v)
+}
+
+// valueOf is the metric of p, NaN for failed points.
+func valueOf(p sensitivity.Point, value func(sensitivity.Point) float64) float64 {
+	if p.Err != nil {
+		return math.NaN()
+	}
+	return value(p)
+}
+
+// curve prints one row per value of x.
+func curve(w io.Writer, x sensitivity.Param, pts []sensitivity.Point) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "%s\troutable\taccepted\tweight\tS_max\tfragmentation\toptimal\t\n", x.Name)
+	for _, p := range pts {
+		if p.Err != nil {
+			fmt.Fprintf(tw, "%g\t-\t-\t-\t-\t-\t-\t\n", p.X)
+			continue
+		}
+		fmt.Fprintf(tw, "%g\t%d\t%d\t%g\t%d\t%.3f\t%v\t\n", p.X, p.Routable, len(p.Plan), p.Weight,
+			p.Plan.SMax(), p.Fragmentation, p.Optimal)
+	}
+	tw.Flush()
+}
+
+// matrix arranges the metric by y (rows) and x (columns).
+func matrix(x, y sensitivity.Param, pts []sensitivity.Point, value func(sensitivity.Point) float64) [][]float64 {
+	z := make([][]float64, len(y.Values))
+	for i := range z {
+		z[i] = make([]float64, len(x.Values))
+		for j := range z[i] {
+			z[i][j] = valueOf(pts[i*len(x.Values)+j], value)
+		}
+	}
+	return z
+}
+
+// grid prints the metric as a table with a row per value of y.
+func grid(w io.Writer, metric string, x, y sensitivity.Param, pts []sensitivity.Point, value func(sensitivity.Point) float64) {
+	fmt.Fprintf(w, "%s by %s (columns) and %s (rows)\n", metric, x.Name, y.Name)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "%s \\ %s\t", y.Name, x.Name)
+	for _, v := range x.Values {
+		fmt.Fprintf(tw, "%g\t", v)
+	}
+	fmt.Fprintln(tw)
+	for i, row := range matrix(x, y, pts, value) {
+		fmt.Fprintf(tw, "%g\t", y.Values[i])
+		for _, v := range row {
+			if math.IsNaN(v) {
+				fmt.Fprint(tw, "-\t")
+			} else {
+				fmt.Fprintf(tw, "%.4g\t", v)
+			}
+		}
+		fmt.Fprintln(tw)
+	}
+	tw.Flush()
+}
+
+func writeCSV(w io.Writer, x sensitivity.Param, y *sensitivity.Param, pts []sensitivity.Point) error {
+	cw := csv.NewWriter(w)
+	head := []string{x.Name}
+	if y != nil {
+		head = append(head, y.Name)
+	}
+	cw.Write(append(head, "routable", "accepted", "weight", "S_max", "fragmentation", "optimal", "error"))
+	f := func(v float64) string { return strconv.FormatFloat(v, 'g', -1, 64) }
+	for _, p := range pts {
+		row := []string{f(p.X)}
+		if y != nil {
+			row = append(row, f(p.Y))
+		}
+		if p.Err != nil {
+			row = append(row, "", "", "", "", "", "", p.Err.Error())
+		} else {
+			row = append(row, strconv.Itoa(p.Routable), strconv.Itoa(len(p.Plan)), f(p.Weight),
+				strconv.Itoa(p.Plan.SMax()), f(p.Fragmentation), strconv.FormatBool(p.Optimal), "")
+		}
+		cw.Write(row)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeFile(path string, to func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := to(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
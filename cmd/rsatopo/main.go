@@ -0,0 +1,75 @@
+// Command rsatopo generates a random or regular topology and writes it as
+// the NODES, LINKS and D of a GMPL data file, with the node coordinates in
+// a comment, ready for traffic and spectrum parameters to be added.
+//
+//	rsatopo -model waxman -n 30 -alpha 0.3 -beta 0.5 -o waxman.dat
+//	rsatopo -model grid -n 12 -cols 4 -span 800km -route-factor 1.3
+package main
+
+import (
+	"flag"
+	"io"
+	"log"
+	"math"
+	"os"
+	"slices"
+
+	"github.com/dilwar-crnlab/hpsr_2025/topology"
+	"github.com/dilwar-crnlab/hpsr_2025/units"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("rsatopo: ")
+	opt := topology.DefaultOptions()
+	model := flag.String("model", "waxman", "generator: waxman, ba, ring, ladder, grid, gabriel")
+	flag.IntVar(&opt.N, "n", opt.N, "number of nodes")
+	flag.Int64Var(&opt.Seed, "seed", opt.Seed, "random seed")
+	flag.Float64Var(&opt.Lat, "lat", opt.Lat, "latitude of the centre of the region, degrees")
+	flag.Float64Var(&opt.Lon, "lon", opt.Lon, "longitude of the centre of the region, degrees")
+	flag.Var(&opt.Span, "span", "side of the square region, e.g. 2000km")
+	flag.Float64Var(&opt.RouteFactor, "route-factor", opt.RouteFactor, "fibre length over great-circle distance")
+	alpha := flag.Float64("alpha", 0.4, "Waxman distance scale")
+	beta := flag.Float64("beta", 0.4, "Waxman link density")
+	m := flag.Int("m", 2, "Barabási–Albert links per new node")
+	cols := flag.Int("cols", 0, "grid columns, 0 for a square grid")
+	out := flag.String("o", "", "output file, standard output if empty")
+	flag.Parse()
+
+	params := map[string]float64{"alpha": *alpha, "beta": *beta, "m": float64(*m)}
+	if *cols > 0 {
+		params["cols"] = float64(*cols)
+	}
+	t, err := topology.Generate(*model, opt, params)
+	if err != nil {
+		log.Fatal(err)
+	}
+	deg := t.Degree()
+	var total units.Distance
+	for _, l := range t.Links {
+		total += t.Length(l)
+	}
+	log.Printf("%s: %d nodes, %d links, degree %d..%d, mean link %v", *model, len(t.Nodes), len(t.Links),
+		slices.Min(deg), slices.Max(deg), units.Distance(math.Round(float64(total)/float64(max(len(t.Links), 1)))))
+	if *out == "" {
+		if err := t.Write(os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if err := writeFile(*out, t.Write); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func writeFile(path string, to func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := to(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,203 @@
+package conflict
+
+import (
+	"github.com/dilwar-crnlab/hpsr_2025/plan"
+	"github.com/dilwar-crnlab/hpsr_2025/spectrum"
+)
+
+// unassigned marks a vertex without a block.
+const unassigned = -1
+
+// lowestStart returns the lowest start of a block of v at or after from
+// that lies inside one zone, keeps the guard band to the blocks of its
+// assigned neighbours and ends by limit. It returns -1 if there is none.
+func (g *Graph) lowestStart(zones []spectrum.Zone, start []int, v, from, limit int) int {
+	w := g.Slots[v]
+	for _, z := range zones {
+		s := max(from, z.Lo)
+		for moved := true; moved; {
+			moved = false
+			for u, su := range start {
+				if su == unassigned || !g.Adj[v][u] {
+					continue
+				}
+				if s < su+g.Slots[u]+g.In.G && su < s+w+g.In.G {
+					s = su + g.Slots[u] + g.In.G
+					moved = true
+				}
+			}
+		}
+		if s+w <= z.Hi && s+w <= limit {
+			return s
+		}
+	}
+	return -1
+}
+
+// plan turns vertex starts into a plan of the assigned vertices.
+func (g *Graph) plan(start []int) plan.Plan {
+	var p plan.Plan
+	for v, s := range start {
+		if s != unassigned {
+			p = append(p, plan.Assignment{T: g.T[v], Route: g.Route[v], Start: s, Slots: g.Slots[v]})
+		}
+	}
+	return p
+}
+
+// DSatur assigns spectrum greedily in the order of DSatur colouring: next
+// is the unassigned vertex whose assigned neighbours occupy the most slots,
+// ties broken by degree and then by size. Each vertex takes the lowest
+// block inside a zone that keeps the guard band to its neighbours; vertices
+// with no such block within N_slots are rejected.
+func (g *Graph) DSatur() plan.Plan {
+	return g.plan(g.dsatur())
+}
+
+func (g *Graph) dsatur() []int {
+	n := g.Len()
+	zones := spectrum.Zones(g.In)
+	start := make([]int, n)
+	for v := range start {
+		start[v] = unassigned
+	}
+	sat := make([]int, n)
+	done := make([]bool, n)
+	for range n {
+		v := g.pick(sat, done)
+		done[v] = true
+		start[v] = g.lowestStart(zones, start, v, 0, g.In.NSlots)
+		if start[v] != unassigned {
+			g.saturate(sat, v)
+		}
+	}
+	return start
+}
+
+// pick returns the next vertex in DSatur order.
+func (g *Graph) pick(sat []int, done []bool) int {
+	v := -1
+	for u := range sat {
+		if done[u] {
+			continue
+		}
+		if v < 0 || sat[u] > sat[v] ||
+			sat[u] == sat[v] && (g.Degree[u] > g.Degree[v] || g.Degree[u] == g.Degree[v] && g.Slots[u] > g.Slots[v]) {
+			v = u
+		}
+	}
+	return v
+}
+
+// saturate adds the block of v to the saturation of its neighbours.
+func (g *Graph) saturate(sat []int, v int) {
+	for u := range sat {
+		if g.Adj[v][u] {
+			sat[u] += g.Slots[v] + g.In.G
+		}
+	}
+}
+
+// Exact searches for an assignment of every vertex inside the zones that
+// minimises S_max, by branch and bound over the order of the blocks'
+// starts. Placing the blocks of any assignment in order of their start,
+// each at the lowest start at or after that of the previous one that keeps
+// the guard band to the blocks placed before, moves no block up, so some
+// optimal assignment is reached by branching over which vertex comes next.
+// The search stops after budget nodes (budget <= 0 means no limit). It
+// returns the best assignment found, whether one was found and whether it
+// is proven optimal.
+func (g *Graph) Exact(budget int) (p plan.Plan, found, optimal bool) {
+	p, found, complete := g.search(budget, false)
+	return p, found, complete && found
+}
+
+// Feasible searches for any assignment of every vertex inside the zones,
+// with the same node budget as Exact. It returns the assignment, whether
+// one was found and whether the answer is proven, i.e. either an
+// assignment was found or the search space was exhausted.
+func (g *Graph) Feasible(budget int) (p plan.Plan, found, proven bool) {
+	p, found, complete := g.search(budget, true)
+	return p, found, found || complete
+}
+
+// search is the branch and bound of Exact; with first set it stops at the
+// first assignment. complete reports that the search was not cut short by
+// the budget.
+func (g *Graph) search(budget int, first bool) (p plan.Plan, found, complete bool) {
+	n := g.Len()
+	zones := spectrum.Zones(g.In)
+	order := g.dsaturOrder()
+	lb := 0
+	if !first {
+		lower, _ := g.MaxWeightClique(budget)
+		lb = g.Span(lower)
+	}
+
+	start := make([]int, n)
+	for v := range start {
+		start[v] = unassigned
+	}
+	best := g.In.NSlots + 1
+	if h := g.plan(g.dsatur()); len(h) == n {
+		best, p, found = h.SMax(), h, true
+	}
+	nodes := 0
+	complete = true
+	var branch func(k, from, span int)
+	branch = func(k, from, span int) {
+		if best <= lb || first && found {
+			return
+		}
+		if budget > 0 && nodes >= budget {
+			complete = false
+			return
+		}
+		nodes++
+		if k == n {
+			best, p, found = span, g.plan(start), true
+			return
+		}
+		// Every block left starts at or after from, and its lowest start
+		// only rises as blocks are added.
+		next := make([]int, n)
+		for v := range next {
+			next[v] = unassigned
+			if start[v] != unassigned {
+				continue
+			}
+			if next[v] = g.lowestStart(zones, start, v, from, best-1); next[v] == unassigned {
+				return
+			}
+		}
+		for _, v := range order {
+			if start[v] != unassigned {
+				continue
+			}
+			start[v] = next[v]
+			branch(k+1, next[v], max(span, next[v]+g.Slots[v]))
+			start[v] = unassigned
+			if best <= lb || first && found {
+				return
+			}
+		}
+	}
+	branch(0, 0, 0)
+	return p, found, complete
+}
+
+// dsaturOrder is the order in which DSatur would pick the vertices if each
+// were assigned, the order in which Exact branches over the next vertex.
+func (g *Graph) dsaturOrder() []int {
+	n := g.Len()
+	sat := make([]int, n)
+	done := make([]bool, n)
+	order := make([]int, 0, n)
+	for range n {
+		v := g.pick(sat, done)
+		done[v] = true
+		order = append(order, v)
+		g.saturate(sat, v)
+	}
+	return order
+}
@@ -0,0 +1,79 @@
+package conflict
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+	"github.com/dilwar-crnlab/hpsr_2025/plan"
+)
+
+// graph loads testdata/data with its zone resized to slots and builds the
+// conflict graph of its shortest routes.
+func graph(t *testing.T, data string, slots int) *Graph {
+	t.Helper()
+	in, err := instance.Load("../testdata/" + data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	in.ScaleZones(slots)
+	return Build(in, plan.Shortest(in))
+}
+
+func TestExact(t *testing.T) {
+	tests := []struct {
+		data string
+		smax int
+	}{
+		{"line4.dat", 7},
+		{"ring5.dat", 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.data, func(t *testing.T) {
+			g := graph(t, tt.data, 20)
+			p, found, optimal := g.Exact(0)
+			if !found || !optimal {
+				t.Fatalf("found %v, optimal %v", found, optimal)
+			}
+			if err := p.Validate(g.In); err != nil {
+				t.Fatalf("invalid plan: %v", err)
+			}
+			if len(p) != g.Len() {
+				t.Errorf("%d of %d requests assigned", len(p), g.Len())
+			}
+			if s := p.SMax(); s != tt.smax {
+				t.Errorf("S_max %d, want %d", s, tt.smax)
+			}
+			if d := g.DSatur(); d.SMax() < tt.smax {
+				t.Errorf("DSatur S_max %d below the optimum %d", d.SMax(), tt.smax)
+			}
+		})
+	}
+}
+
+func TestFeasible(t *testing.T) {
+	tests := []struct {
+		data  string
+		slots int
+		found bool
+	}{
+		{"line4.dat", 7, true},
+		{"line4.dat", 6, false},
+		{"ring5.dat", 3, true},
+		{"ring5.dat", 2, false},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s in %d", tt.data, tt.slots), func(t *testing.T) {
+			g := graph(t, tt.data, tt.slots)
+			p, found, proven := g.Feasible(0)
+			if found != tt.found || !proven {
+				t.Fatalf("found %v, proven %v; want found %v, proven", found, proven, tt.found)
+			}
+			if found {
+				if err := p.Validate(g.In); err != nil {
+					t.Errorf("invalid plan: %v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+// Package conflict builds the conflict graph of routed requests: one vertex
+// per request, an edge between requests whose routes share a link. With the
+// routes fixed, spectrum assignment is interval colouring of this graph.
+package conflict
+
+import (
+	"sort"
+
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+	"github.com/dilwar-crnlab/hpsr_2025/plan"
+)
+
+// Graph is the conflict graph of a routing.
+type Graph struct {
+	In     *instance.Instance
+	T      []instance.Demand // vertex -> request, in TRAFFIC order
+	Route  []plan.Route
+	Slots  []int // N_req of each vertex
+	Adj    [][]bool
+	Degree []int
+}
+
+// Build returns the conflict graph of the requests routed in rt.
+func Build(in *instance.Instance, rt map[instance.Demand]plan.Route) *Graph {
+	g := &Graph{In: in}
+	var links [][]instance.Link
+	for _, t := range in.Traffic {
+		r, ok := rt[t]
+		if !ok {
+			continue
+		}
+		g.T = append(g.T, t)
+		g.Route = append(g.Route, r)
+		g.Slots = append(g.Slots, plan.Slots(in, t, r))
+		links = append(links, plan.Links(in, t, r))
+	}
+	n := len(g.T)
+	g.Adj = make([][]bool, n)
+	g.Degree = make([]int, n)
+	for i := range g.Adj {
+		g.Adj[i] = make([]bool, n)
+	}
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			if share(links[i], links[j]) {
+				g.Adj[i][j], g.Adj[j][i] = true, true
+				g.Degree[i]++
+				g.Degree[j]++
+			}
+		}
+	}
+	return g
+}
+
+func share(a, b []instance.Link) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Len is the number of vertices.
+func (g *Graph) Len() int { return len(g.T) }
+
+// Edges is the number of edges.
+func (g *Graph) Edges() int {
+	n := 0
+	for _, d := range g.Degree {
+		n += d
+	}
+	return n / 2
+}
+
+// Span is the spectrum needed by the requests of a clique laid side by side
+// with guard bands between them.
+func (g *Graph) Span(clique []int) int {
+	if len(clique) == 0 {
+		return 0
+	}
+	n := g.In.G * (len(clique) - 1)
+	for _, v := range clique {
+		n += g.Slots[v]
+	}
+	return n
+}
+
+// exactCliqueLimit is the largest graph searched exhaustively by
+// MaxWeightClique.
+const exactCliqueLimit = 200
+
+// MaxWeightClique returns a clique of maximum Span. Graphs above
+// exactCliqueLimit vertices, or searches exceeding budget branch nodes
+// (budget <= 0 means no limit), fall back to the best clique found, and
+// exact reports whether the result is proven optimal.
+func (g *Graph) MaxWeightClique(budget int) (clique []int, exact bool) {
+	n := g.Len()
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	// Heavy, well connected vertices first find good cliques early.
+	sort.Slice(order, func(a, b int) bool {
+		wa, wb := g.Slots[order[a]]*(g.Degree[order[a]]+1), g.Slots[order[b]]*(g.Degree[order[b]]+1)
+		if wa != wb {
+			return wa > wb
+		}
+		return order[a] < order[b]
+	})
+	best := g.greedyClique(order)
+	if n > exactCliqueLimit {
+		return best, false
+	}
+	w := func(v int) int { return g.Slots[v] + g.In.G }
+	bestW := g.Span(best) + g.In.G
+	nodes := 0
+	exact = true
+	var cur []int
+	var grow func(cand []int, curW int)
+	grow = func(cand []int, curW int) {
+		if budget > 0 && nodes >= budget {
+			exact = false
+			return
+		}
+		nodes++
+		if curW > bestW {
+			bestW = curW
+			best = append([]int(nil), cur...)
+		}
+		rest := 0
+		for _, v := range cand {
+			rest += w(v)
+		}
+		for i, v := range cand {
+			if curW+rest <= bestW {
+				return
+			}
+			rest -= w(v)
+			var next []int
+			for _, u := range cand[i+1:] {
+				if g.Adj[v][u] {
+					next = append(next, u)
+				}
+			}
+			cur = append(cur, v)
+			grow(next, curW+w(v))
+			cur = cur[:len(cur)-1]
+		}
+	}
+	grow(order, 0)
+	sort.Ints(best)
+	return best, exact
+}
+
+// greedyClique grows a clique along order.
+func (g *Graph) greedyClique(order []int) []int {
+	var c []int
+	for _, v := range order {
+		ok := true
+		for _, u := range c {
+			if !g.Adj[u][v] {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			c = append(c, v)
+		}
+	}
+	return c
+}
@@ -0,0 +1,280 @@
+// Package cp solves the routing and spectrum assignment problem of ilp.mod
+// by constraint programming. Every request is an optional interval with one
+// alternative per reach-feasible (path, modulation); the start domain of an
+// alternative holds the slots at which its N_req block fits inside a zone.
+// Alternatives sharing a link are in a no-overlap relation with guard band
+// G, propagated by removing the starts a fixed block rules out. A
+// depth-first search fixes the accepted blocks in order of their start,
+// each at the lowest start left at or after that of the previous one, and
+// maximises the class-weighted number of accepted requests, then minimises
+// S_max. Fixing the blocks of any plan in that order moves no block up, so
+// branching over which request and alternative comes next, with every
+// request not fixed by then rejected, reaches an optimal plan.
+package cp
+
+import (
+	"sort"
+	"time"
+
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+	"github.com/dilwar-crnlab/hpsr_2025/plan"
+	"github.com/dilwar-crnlab/hpsr_2025/spectrum"
+)
+
+// Options limits the search.
+type Options struct {
+	Budget   int       // search nodes, <= 0 for no limit
+	Deadline time.Time // zero for no limit
+}
+
+// Result is the outcome of a search.
+type Result struct {
+	Plan    plan.Plan
+	Optimal bool // the search space was exhausted
+	Nodes   int
+}
+
+type alt struct {
+	route plan.Route
+	slots int
+	// conflicts lists the alternatives of other requests sharing a link.
+	conflicts []ref
+}
+
+type ref struct{ task, alt int }
+
+type task struct {
+	t    instance.Demand
+	w    float64
+	alts []alt
+}
+
+const (
+	undecided int8 = iota
+	accepted
+	rejected
+)
+
+type state struct {
+	status []int8
+	alt    []int
+	start  []int
+	doms   [][]domain
+	weight float64
+	smax   int
+}
+
+func (st *state) clone() *state {
+	c := &state{
+		status: append([]int8(nil), st.status...),
+		alt:    append([]int(nil), st.alt...),
+		start:  append([]int(nil), st.start...),
+		doms:   make([][]domain, len(st.doms)),
+		weight: st.weight,
+		smax:   st.smax,
+	}
+	for i, ds := range st.doms {
+		c.doms[i] = make([]domain, len(ds))
+		for a, d := range ds {
+			c.doms[i][a] = d.clone()
+		}
+	}
+	return c
+}
+
+type solver struct {
+	in    *instance.Instance
+	tasks []task
+	opt   Options
+	nodes int
+	stop  bool
+
+	best      *state
+	bestW     float64
+	bestSMax  int
+	exhausted bool
+}
+
+// Solve searches for a plan of in.
+func Solve(in *instance.Instance, opt Options) Result {
+	s := &solver{in: in, opt: opt, bestW: -1}
+	root := s.build()
+	s.exhausted = true
+	s.search(root, 0, -1)
+	res := Result{Optimal: s.exhausted, Nodes: s.nodes}
+	if s.best != nil {
+		for i, tk := range s.tasks {
+			if s.best.status[i] == accepted {
+				a := tk.alts[s.best.alt[i]]
+				res.Plan = append(res.Plan, plan.Assignment{T: tk.t, Route: a.route, Start: s.best.start[i], Slots: a.slots})
+			}
+		}
+	}
+	return res
+}
+
+// build creates the tasks and the root state with zone-restricted domains.
+func (s *solver) build() *state {
+	in := s.in
+	zones := spectrum.Zones(in)
+	root := &state{}
+	var links [][][]instance.Link
+	for _, t := range in.Traffic {
+		rs := plan.Candidates(in, t)
+		if len(rs) == 0 {
+			continue
+		}
+		tk := task{t: t, w: in.Weight(t)}
+		var ds []domain
+		var ls [][]instance.Link
+		for _, r := range rs {
+			n := plan.Slots(in, t, r)
+			d := newDomain(in.NSlots)
+			for _, z := range zones {
+				for st := z.Lo; st+n <= z.Hi; st++ {
+					d.add(st)
+				}
+			}
+			tk.alts = append(tk.alts, alt{route: r, slots: n})
+			ds = append(ds, d)
+			ls = append(ls, plan.Links(in, t, r))
+		}
+		s.tasks = append(s.tasks, tk)
+		root.doms = append(root.doms, ds)
+		links = append(links, ls)
+	}
+	for i := range s.tasks {
+		for a := range s.tasks[i].alts {
+			for j := range s.tasks {
+				if j == i {
+					continue
+				}
+				for b := range s.tasks[j].alts {
+					if sharesLink(links[i][a], links[j][b]) {
+						s.tasks[i].alts[a].conflicts = append(s.tasks[i].alts[a].conflicts, ref{j, b})
+					}
+				}
+			}
+		}
+	}
+	n := len(s.tasks)
+	root.status = make([]int8, n)
+	root.alt = make([]int, n)
+	root.start = make([]int, n)
+	for i := range s.tasks {
+		s.prune(root, i)
+	}
+	return root
+}
+
+func sharesLink(a, b []instance.Link) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fix accepts task i on alternative a at start st and propagates the
+// no-overlap constraints to the undecided tasks.
+func (s *solver) fix(st *state, i, a, start int) {
+	al := s.tasks[i].alts[a]
+	st.status[i], st.alt[i], st.start[i] = accepted, a, start
+	st.weight += s.tasks[i].w
+	st.smax = max(st.smax, start+al.slots)
+	g := s.in.G
+	touched := map[int]bool{}
+	for _, c := range al.conflicts {
+		if st.status[c.task] != undecided {
+			continue
+		}
+		nb := s.tasks[c.task].alts[c.alt].slots
+		// A block of c at u overlaps unless u+nb+G <= start or start+n+G <= u.
+		if st.doms[c.task][c.alt].removeRange(start-nb-g+1, start+al.slots+g) {
+			touched[c.task] = true
+		}
+	}
+	for j := range touched {
+		s.prune(st, j)
+	}
+}
+
+// prune rejects task i when none of its alternatives has a start left.
+func (s *solver) prune(st *state, i int) {
+	for _, d := range st.doms[i] {
+		if !d.empty() {
+			return
+		}
+	}
+	st.status[i] = rejected
+}
+
+func (s *solver) limit() bool {
+	if s.opt.Budget > 0 && s.nodes >= s.opt.Budget ||
+		!s.opt.Deadline.IsZero() && s.nodes%256 == 0 && time.Now().After(s.opt.Deadline) {
+		s.stop = true
+	}
+	return s.stop
+}
+
+func (s *solver) better(w float64, smax int) bool {
+	return w > s.bestW || w == s.bestW && smax < s.bestSMax
+}
+
+// search explores the plans that extend st with blocks starting at or
+// after from, the start of the block last fixed, that of task last. Of
+// blocks with equal starts, which cannot share a link, only the order of
+// increasing task is tried.
+func (s *solver) search(st *state, from, last int) {
+	if s.limit() {
+		s.exhausted = false
+		return
+	}
+	s.nodes++
+	// Rejecting every undecided task completes a plan.
+	if s.better(st.weight, st.smax) {
+		s.best, s.bestW, s.bestSMax = st, st.weight, st.smax
+	}
+	type next struct{ task, alt, start int }
+	var nexts []next
+	bound := st.weight
+	for i, tk := range s.tasks {
+		if st.status[i] != undecided {
+			continue
+		}
+		open := false
+		for a := range tk.alts {
+			u := st.doms[i][a].atLeast(from)
+			if u < 0 || u == from && i < last {
+				continue
+			}
+			nexts = append(nexts, next{i, a, u})
+			open = true
+		}
+		if open {
+			bound += tk.w
+		}
+	}
+	// S_max can only grow from here, so st.smax is its lower bound.
+	if !s.better(bound, st.smax) {
+		return
+	}
+	sort.SliceStable(nexts, func(x, y int) bool {
+		a, b := nexts[x], nexts[y]
+		if wa, wb := s.tasks[a.task].w, s.tasks[b.task].w; wa != wb {
+			return wa > wb
+		}
+		return a.start < b.start
+	})
+	for _, nx := range nexts {
+		c := st.clone()
+		s.fix(c, nx.task, nx.alt, nx.start)
+		s.search(c, nx.start, nx.task)
+		if s.stop {
+			return
+		}
+	}
+}
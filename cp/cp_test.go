@@ -0,0 +1,36 @@
+package cp
+
+import (
+	"testing"
+
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+)
+
+func TestSolve(t *testing.T) {
+	tests := []struct {
+		data   string
+		weight float64
+		smax   int
+	}{
+		{"path3.dat", 6, 10},
+		{"line4.dat", 4, 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.data, func(t *testing.T) {
+			in, err := instance.Load("../testdata/" + tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res := Solve(in, Options{})
+			if err := res.Plan.Validate(in); err != nil {
+				t.Fatalf("invalid plan: %v", err)
+			}
+			if !res.Optimal {
+				t.Errorf("search not exhausted")
+			}
+			if w, s := res.Plan.Weight(in), res.Plan.SMax(); w != tt.weight || s != tt.smax {
+				t.Errorf("weight %g, S_max %d; want %g, %d", w, s, tt.weight, tt.smax)
+			}
+		})
+	}
+}
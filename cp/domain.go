@@ -0,0 +1,46 @@
+package cp
+
+import "math/bits"
+
+// domain is a set of start slots.
+type domain []uint64
+
+func newDomain(n int) domain { return make(domain, (n+63)/64) }
+
+func (d domain) has(s int) bool { return s >= 0 && s/64 < len(d) && d[s/64]&(1<<(s%64)) != 0 }
+func (d domain) add(s int)      { d[s/64] |= 1 << (s % 64) }
+
+// removeRange removes [lo,hi) and reports whether anything was removed.
+func (d domain) removeRange(lo, hi int) bool {
+	lo, hi = max(lo, 0), min(hi, len(d)*64)
+	changed := false
+	for s := lo; s < hi; s++ {
+		if d.has(s) {
+			d[s/64] &^= 1 << (s % 64)
+			changed = true
+		}
+	}
+	return changed
+}
+
+func (d domain) empty() bool {
+	for _, w := range d {
+		if w != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// atLeast returns the smallest start in d that is at least s, or -1.
+func (d domain) atLeast(s int) int {
+	for s = max(s, 0); s < len(d)*64; s++ {
+		if w := d[s/64] >> (s % 64); w != 0 {
+			return s + bits.TrailingZeros64(w)
+		}
+		s |= 63
+	}
+	return -1
+}
+
+func (d domain) clone() domain { return append(domain(nil), d...) }
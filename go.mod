@@ -0,0 +1,3 @@
+module github.com/dilwar-crnlab/hpsr_2025
+
+go 1.22
@@ -1,7 +1,7 @@
 /* FIRST_ILP.mod */
 /* 
    ILP for Zone FLF 
-   This model maximizes the number of accepted connection requests.
+   This model maximizes the (class-weighted) number of accepted connection requests.
    It selects a single candidate path among the K-shortest for each request,
    chooses a modulation level (subject to transmission reach), and allocates 
    contiguous frequency slots within zones. Requests are assigned to either 
@@ -27,6 +27,28 @@ param C_z {ZONES} > 0;            /* Capacity (in slots) of each zone */
 
 param M_big integer > 0;  /* A sufficiently large constant, e.g., N_slots + max_allocation_length */
 
+/* Service classes (e.g. gold, silver, bronze). Each traffic request belongs to one class,
+   and W gives the priority weight of an accepted request of that class in the objective.
+   Without class data every request is in the single class 'std' with weight 1.
+*/
+set CLASSES default {'std'};
+param W {CLASSES} > 0 default 1;                    /* Priority weight of each class */
+param Class {TRAFFIC} symbolic in CLASSES default 'std';  /* Service class of each request */
+
+/* Traffic uncertainty (Bertsimas-Sim): T_sd is a forecast that may be exceeded by up to
+   T_hat, and at most Gamma requests deviate at the same time. This model plans for the
+   nominal T_sd; the robust counterpart is generated by the Go planner (rsarobust).
+*/
+param T_hat {TRAFFIC} >= 0 default 0;   /* Maximum deviation of T_sd */
+param Gamma >= 0 default 0;             /* Budget of uncertainty */
+
+/* Traffic scenarios for two-stage stochastic planning (Go planner rsastoch): scenario w
+   occurs with probability Prob[w] and has demand T_scen[s,d,w]. This model ignores them.
+*/
+set SCENARIOS default {};
+param Prob {SCENARIOS} >= 0 default 1 / card(SCENARIOS);
+param T_scen {(s,d) in TRAFFIC, w in SCENARIOS} > 0 default T_sd[s,d];
+
 
 /* Candidate paths: For each traffic request t, we have a set PATHS[t] of candidate paths */
 set PATHS {t in TRAFFIC};
@@ -79,8 +101,11 @@ var S_max integer >= 0;
 var y {t1 in TRAFFIC, t2 in TRAFFIC: t1 < t2}, binary;
 
 /* --- Objective --- */
+/* Maximize the priority-weighted number of accepted requests. With the default
+   class data this is the number of accepted requests.
+*/
 maximize TotalAccepted:
-    sum {t in TRAFFIC} Accept[t];
+    sum {t in TRAFFIC} W[Class[t]] * Accept[t];
 
 /* --- Constraints --- */
 
@@ -0,0 +1,290 @@
+package instance
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/dilwar-crnlab/hpsr_2025/units"
+)
+
+// Clone returns a deep copy of in.
+func (in *Instance) Clone() *Instance {
+	c := *in
+	c.Nodes = append([]string(nil), in.Nodes...)
+	c.Links = append([]Link(nil), in.Links...)
+	c.Traffic = append([]Demand(nil), in.Traffic...)
+	c.Modulations = append([]string(nil), in.Modulations...)
+	c.Zones = append([]string(nil), in.Zones...)
+	c.Classes = append([]string(nil), in.Classes...)
+	c.Scenarios = append([]string(nil), in.Scenarios...)
+	c.Prob = copyMap(in.Prob)
+	c.TScen = map[string]map[Demand]units.DataRate{}
+	for w, ts := range in.TScen {
+		c.TScen[w] = copyMap(ts)
+	}
+	c.D = copyMap(in.D)
+	c.T = copyMap(in.T)
+	c.THat = copyMap(in.THat)
+	c.R = copyMap(in.R)
+	c.CZ = copyMap(in.CZ)
+	c.NReq = copyMap(in.NReq)
+	c.Class = copyMap(in.Class)
+	c.W = copyMap(in.W)
+	c.Paths = map[Demand][]string{}
+	for k, v := range in.Paths {
+		c.Paths[k] = append([]string(nil), v...)
+	}
+	c.PathLinks = map[PathKey][]Link{}
+	for k, v := range in.PathLinks {
+		c.PathLinks[k] = append([]Link(nil), v...)
+	}
+	c.FeasMod = map[PathKey][]string{}
+	for k, v := range in.FeasMod {
+		c.FeasMod[k] = append([]string(nil), v...)
+	}
+	return &c
+}
+
+func copyMap[K comparable, V any](m map[K]V) map[K]V {
+	c := make(map[K]V, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+// ScaleZones resizes the zones in proportion to their current widths so
+// that together they cover n slots, and sets N_slots to n. The last zone
+// takes the rounding remainder.
+func (in *Instance) ScaleZones(n int) {
+	total := 0
+	for _, z := range in.Zones {
+		total += in.CZ[z]
+	}
+	if total > 0 {
+		left := n
+		for i, z := range in.Zones {
+			w := int(math.Round(float64(in.CZ[z]) * float64(n) / float64(total)))
+			if i == len(in.Zones)-1 || w > left {
+				w = left
+			}
+			in.CZ[z] = w
+			left -= w
+		}
+	}
+	in.NSlots = n
+}
+
+// Efficiency infers for every modulation the slot efficiency e_m of the
+// relation N_req = ceil(T_sd / (C * e_m)) from the N_req values of the
+// instance: the lowest e_m consistent with all of them, which reproduces
+// them whenever they are consistent at all. Modulations without N_req
+// values are missing from the result.
+func (in *Instance) Efficiency() map[string]float64 {
+	eff := map[string]float64{}
+	for k, n := range in.NReq {
+		if e := float64(in.T[k.T]/in.C) / float64(n); e > eff[k.M] {
+			eff[k.M] = e
+		}
+	}
+	return eff
+}
+
+// Slots is ceil(T_sd / (C * e)), the slots t needs at efficiency e.
+func (in *Instance) Slots(t Demand, e float64) int {
+	return int(math.Ceil(float64(in.T[t]/in.C)/e - 1e-9))
+}
+
+// Derive re-derives the data that depends on K and R. With reroute, PATHS
+// become the K shortest loopless paths of every demand by distance D,
+// named p1..pK; otherwise the paths are kept. FEAS_MOD becomes the
+// modulations whose reach R covers each path, and N_req is kept for paths
+// and modulations that existed before and otherwise computed from eff as
+// ceil(T_sd / (C * e_m)). Efficiency gives eff for the modulations of the
+// instance.
+func (in *Instance) Derive(eff map[string]float64, reroute bool) error {
+	old := map[string]int{} // by demand, link sequence and modulation
+	for k, n := range in.NReq {
+		old[fmt.Sprint(k.T, in.canonicalPath(PathKey{k.T, k.P}), k.M)] = n
+	}
+	if reroute {
+		in.Paths = map[Demand][]string{}
+		in.PathLinks = map[PathKey][]Link{}
+		for _, t := range in.Traffic {
+			for i, ls := range in.KShortest(t.S, t.D, in.K) {
+				p := fmt.Sprintf("p%d", i+1)
+				in.Paths[t] = append(in.Paths[t], p)
+				in.PathLinks[PathKey{t, p}] = ls
+			}
+		}
+	}
+	in.FeasMod = map[PathKey][]string{}
+	in.NReq = map[ModKey]int{}
+	for _, t := range in.Traffic {
+		for _, p := range in.Paths[t] {
+			k := PathKey{t, p}
+			d := in.PathDist(t, p)
+			for _, m := range in.Modulations {
+				if d > in.R[m] {
+					continue
+				}
+				n, ok := old[fmt.Sprint(t, in.canonicalPath(k), m)]
+				if !ok {
+					e, known := eff[m]
+					if !known {
+						return fmt.Errorf("modulation %s: no slot efficiency to derive N_req", m)
+					}
+					n = in.Slots(t, e)
+				}
+				in.FeasMod[k] = append(in.FeasMod[k], m)
+				in.NReq[ModKey{t, p, m}] = n
+			}
+		}
+	}
+	return nil
+}
+
+func (in *Instance) canonicalPath(k PathKey) []Link {
+	var ls []Link
+	for _, l := range in.PathLinks[k] {
+		ls = append(ls, in.Canonical(l))
+	}
+	return ls
+}
+
+// KShortest returns up to k shortest loopless paths from s to d by link
+// distance (Yen's algorithm), as links in their LINKS orientation.
+func (in *Instance) KShortest(s, d string, k int) [][]Link {
+	type path struct {
+		nodes []string
+		dist  units.Distance
+	}
+	var found []path
+	var cands []path
+	seen := map[string]bool{}
+	if ns, dist, ok := in.shortest(s, d, nil, nil); ok && k > 0 {
+		found = append(found, path{ns, dist})
+	}
+	for len(found) > 0 && len(found) < k {
+		last := found[len(found)-1].nodes
+		for i := 0; i < len(last)-1; i++ {
+			root := last[:i+1]
+			bannedLinks := map[Link]bool{}
+			for _, p := range found {
+				if len(p.nodes) > i && equal(p.nodes[:i+1], root) {
+					bannedLinks[in.Canonical(Link{p.nodes[i], p.nodes[i+1]})] = true
+				}
+			}
+			bannedNodes := map[string]bool{}
+			for _, n := range root[:i] {
+				bannedNodes[n] = true
+			}
+			spur, _, ok := in.shortest(root[i], d, bannedNodes, bannedLinks)
+			if !ok {
+				continue
+			}
+			ns := append(append([]string(nil), root[:i]...), spur...)
+			key := fmt.Sprint(ns)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			cands = append(cands, path{ns, in.nodeDist(ns)})
+		}
+		if len(cands) == 0 {
+			break
+		}
+		sort.SliceStable(cands, func(a, b int) bool { return cands[a].dist < cands[b].dist })
+		found = append(found, cands[0])
+		cands = cands[1:]
+	}
+	out := make([][]Link, len(found))
+	for i, p := range found {
+		for j := 0; j+1 < len(p.nodes); j++ {
+			out[i] = append(out[i], in.Canonical(Link{p.nodes[j], p.nodes[j+1]}))
+		}
+	}
+	return out
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (in *Instance) nodeDist(ns []string) units.Distance {
+	var d units.Distance
+	for i := 0; i+1 < len(ns); i++ {
+		d += in.Dist(Link{ns[i], ns[i+1]})
+	}
+	return d
+}
+
+// shortest runs Dijkstra from s to d avoiding the banned nodes and links.
+func (in *Instance) shortest(s, d string, bannedNodes map[string]bool, bannedLinks map[Link]bool) ([]string, units.Distance, bool) {
+	adj := map[string][]string{}
+	for _, l := range in.Links {
+		if bannedLinks[l] || bannedNodes[l.I] || bannedNodes[l.J] {
+			continue
+		}
+		adj[l.I] = append(adj[l.I], l.J)
+		adj[l.J] = append(adj[l.J], l.I)
+	}
+	dist := map[string]units.Distance{s: 0}
+	prev := map[string]string{}
+	done := map[string]bool{}
+	q := &nodeQueue{{s, 0}}
+	for q.Len() > 0 {
+		u := heap.Pop(q).(nodeItem)
+		if done[u.node] {
+			continue
+		}
+		done[u.node] = true
+		if u.node == d {
+			break
+		}
+		for _, v := range adj[u.node] {
+			nd := u.dist + in.Dist(Link{u.node, v})
+			if old, ok := dist[v]; !done[v] && (!ok || nd < old) {
+				dist[v], prev[v] = nd, u.node
+				heap.Push(q, nodeItem{v, nd})
+			}
+		}
+	}
+	if !done[d] {
+		return nil, 0, false
+	}
+	ns := []string{d}
+	for n := d; n != s; {
+		n = prev[n]
+		ns = append([]string{n}, ns...)
+	}
+	return ns, dist[d], true
+}
+
+type nodeItem struct {
+	node string
+	dist units.Distance
+}
+
+type nodeQueue []nodeItem
+
+func (q nodeQueue) Len() int           { return len(q) }
+func (q nodeQueue) Less(a, b int) bool { return q[a].dist < q[b].dist }
+func (q nodeQueue) Swap(a, b int)      { q[a], q[b] = q[b], q[a] }
+func (q *nodeQueue) Push(x any)        { *q = append(*q, x.(nodeItem)) }
+func (q *nodeQueue) Pop() any {
+	old := *q
+	x := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return x
+}
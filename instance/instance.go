@@ -0,0 +1,258 @@
+// Package instance holds the problem data of the zone-FLF RSA model
+// (ilp.mod) and reads/writes it in the GMPL data format of data.dat.
+package instance
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/dilwar-crnlab/hpsr_2025/units"
+)
+
+// Link is an undirected fibre link between two nodes, stored in the
+// orientation in which it appears in LINKS.
+type Link struct {
+	I, J string
+}
+
+func (l Link) String() string { return "[" + l.I + "," + l.J + "]" }
+
+// Reverse returns the link with its end points swapped.
+func (l Link) Reverse() Link { return Link{l.J, l.I} }
+
+// Demand is a traffic request (s,d) of the TRAFFIC set.
+type Demand struct {
+	S, D string
+}
+
+func (d Demand) String() string { return "(" + d.S + "," + d.D + ")" }
+
+// PathKey indexes the per-path sets PATH_LINKS and FEAS_MOD.
+type PathKey struct {
+	T Demand
+	P string
+}
+
+// ModKey indexes N_req.
+type ModKey struct {
+	T    Demand
+	P, M string
+}
+
+// Instance mirrors the sets and parameters declared in ilp.mod. Physical
+// quantities carry their units; slot counts stay plain ints, as they
+// index the spectrum.
+type Instance struct {
+	Nodes       []string
+	Links       []Link
+	D           map[Link]units.Distance // link distances
+	Traffic     []Demand
+	T           map[Demand]units.DataRate // T_sd
+	C           units.DataRate            // capacity of one slot
+	G           int
+	K           int
+	Modulations []string
+	R           map[string]units.Distance // maximum reach per modulation
+	Zones       []string
+	CZ          map[string]int // C_z
+	NSlots      int
+	MBig        int // M_big, zero when not given
+	Paths       map[Demand][]string
+	PathLinks   map[PathKey][]Link
+	FeasMod     map[PathKey][]string
+	NReq        map[ModKey]int
+
+	// Traffic uncertainty: T_sd may exceed its forecast by up to THat
+	// (T_hat), and at most Gamma requests deviate at once.
+	THat  map[Demand]units.DataRate
+	Gamma float64
+
+	// Traffic scenarios with their probabilities; TScen[w][t] is T_sd of t
+	// in scenario w, T_sd itself where not given.
+	Scenarios []string
+	Prob      map[string]float64
+	TScen     map[string]map[Demand]units.DataRate
+
+	// Service classes. Classes is ordered by decreasing priority weight.
+	Classes []string
+	Class   map[Demand]string  // class of each traffic request
+	W       map[string]float64 // objective weight of each class
+}
+
+// New returns an empty instance with all maps allocated.
+func New() *Instance {
+	return &Instance{
+		D:         map[Link]units.Distance{},
+		T:         map[Demand]units.DataRate{},
+		THat:      map[Demand]units.DataRate{},
+		Prob:      map[string]float64{},
+		TScen:     map[string]map[Demand]units.DataRate{},
+		R:         map[string]units.Distance{},
+		CZ:        map[string]int{},
+		Paths:     map[Demand][]string{},
+		PathLinks: map[PathKey][]Link{},
+		FeasMod:   map[PathKey][]string{},
+		NReq:      map[ModKey]int{},
+		Class:     map[Demand]string{},
+		W:         map[string]float64{},
+	}
+}
+
+// HasLink reports whether l (in either orientation) belongs to LINKS.
+func (in *Instance) HasLink(l Link) bool {
+	_, ok := in.canonical(l)
+	return ok
+}
+
+// Canonical returns the orientation of l that appears in LINKS.
+func (in *Instance) Canonical(l Link) Link {
+	c, _ := in.canonical(l)
+	return c
+}
+
+func (in *Instance) canonical(l Link) (Link, bool) {
+	if _, ok := in.D[l]; ok {
+		return l, true
+	}
+	if _, ok := in.D[l.Reverse()]; ok {
+		return l.Reverse(), true
+	}
+	return l, false
+}
+
+// Dist returns the distance of l in either orientation.
+func (in *Instance) Dist(l Link) units.Distance {
+	return in.D[in.Canonical(l)]
+}
+
+// PathDist is the total distance of candidate path p of t, as computed by
+// constraint ComputePathDist of the model.
+func (in *Instance) PathDist(t Demand, p string) units.Distance {
+	var d units.Distance
+	for _, l := range in.PathLinks[PathKey{t, p}] {
+		d += in.Dist(l)
+	}
+	return d
+}
+
+// ScenarioT returns T_sd of t in scenario w.
+func (in *Instance) ScenarioT(w string, t Demand) units.DataRate {
+	if v, ok := in.TScen[w][t]; ok {
+		return v
+	}
+	return in.T[t]
+}
+
+// ClassOf returns the service class of t, or "" when no classes are defined.
+func (in *Instance) ClassOf(t Demand) string {
+	return in.Class[t]
+}
+
+// Weight returns the objective weight of t's class (1 when unset).
+func (in *Instance) Weight(t Demand) float64 {
+	if w, ok := in.W[in.Class[t]]; ok {
+		return w
+	}
+	return 1
+}
+
+// sortClasses orders Classes by decreasing weight, keeping the declaration
+// order between equal weights.
+func (in *Instance) sortClasses() {
+	sort.SliceStable(in.Classes, func(a, b int) bool {
+		return in.classWeight(in.Classes[a]) > in.classWeight(in.Classes[b])
+	})
+}
+
+func (in *Instance) classWeight(c string) float64 {
+	if w, ok := in.W[c]; ok {
+		return w
+	}
+	return 1
+}
+
+// Validate checks the cross references between sets and parameters.
+func (in *Instance) Validate() error {
+	nodes := map[string]bool{}
+	for _, n := range in.Nodes {
+		nodes[n] = true
+	}
+	for _, l := range in.Links {
+		if !nodes[l.I] || !nodes[l.J] {
+			return fmt.Errorf("link %v: unknown node", l)
+		}
+		d, ok := in.D[l]
+		if !ok {
+			return fmt.Errorf("link %v: missing distance D", l)
+		}
+		if d < 0 {
+			return fmt.Errorf("link %v: negative distance D", l)
+		}
+	}
+	mods := map[string]bool{}
+	for _, m := range in.Modulations {
+		mods[m] = true
+		r, ok := in.R[m]
+		if !ok {
+			return fmt.Errorf("modulation %s: missing reach R", m)
+		}
+		if r < 0 {
+			return fmt.Errorf("modulation %s: negative reach R", m)
+		}
+	}
+	scens := map[string]bool{}
+	for _, w := range in.Scenarios {
+		scens[w] = true
+		if in.Prob[w] < 0 {
+			return fmt.Errorf("scenario %s: negative probability", w)
+		}
+	}
+	for w, ts := range in.TScen {
+		if !scens[w] {
+			return fmt.Errorf("T_scen: scenario %s not in SCENARIOS", w)
+		}
+		for t, v := range ts {
+			if _, ok := in.T[t]; !ok {
+				return fmt.Errorf("T_scen: %v not in TRAFFIC", t)
+			}
+			if v <= 0 {
+				return fmt.Errorf("T_scen: %v in scenario %s is not positive", t, w)
+			}
+		}
+	}
+	classes := map[string]bool{}
+	for _, c := range in.Classes {
+		classes[c] = true
+	}
+	for _, t := range in.Traffic {
+		if _, ok := in.T[t]; !ok {
+			return fmt.Errorf("traffic %v: missing demand T_sd", t)
+		}
+		if in.THat[t] < 0 {
+			return fmt.Errorf("traffic %v: negative deviation T_hat", t)
+		}
+		if c, ok := in.Class[t]; ok && !classes[c] {
+			return fmt.Errorf("traffic %v: class %q not in CLASSES", t, c)
+		}
+		for _, p := range in.Paths[t] {
+			k := PathKey{t, p}
+			if len(in.PathLinks[k]) == 0 {
+				return fmt.Errorf("traffic %v path %s: no PATH_LINKS", t, p)
+			}
+			for _, l := range in.PathLinks[k] {
+				if !in.HasLink(l) {
+					return fmt.Errorf("traffic %v path %s: link %v not in LINKS", t, p, l)
+				}
+			}
+			for _, m := range in.FeasMod[k] {
+				if !mods[m] {
+					return fmt.Errorf("traffic %v path %s: unknown modulation %s", t, p, m)
+				}
+				if in.NReq[ModKey{t, p, m}] <= 0 {
+					return fmt.Errorf("traffic %v path %s modulation %s: missing N_req", t, p, m)
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package instance
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestValidateNegative(t *testing.T) {
+	b, err := os.ReadFile("../testdata/single.dat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := string(b)
+	if _, err := Parse(strings.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct{ old, new, want string }{
+		{"[0,1] 100", "[0,1] -100", "negative distance D"},
+		{"m1 1000", "m1 -1000", "negative reach R"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(strings.NewReader(strings.Replace(data, tt.old, tt.new, 1)))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: got error %v, want %q", tt.new, err, tt.want)
+		}
+	}
+}
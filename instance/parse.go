@@ -0,0 +1,327 @@
+package instance
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/dilwar-crnlab/hpsr_2025/units"
+)
+
+// Load reads a GMPL data file such as data.dat.
+func Load(path string) (*Instance, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	in, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return in, nil
+}
+
+// Parse reads the subset of the GMPL data language used by data.dat:
+// plain and indexed "set" statements, scalar "param" statements and
+// "param NAME := key... value, ..." tables with tuple keys in (..) or [..].
+func Parse(r io.Reader) (*Instance, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	toks, err := tokenize(string(src))
+	if err != nil {
+		return nil, err
+	}
+	in := New()
+	for len(toks) > 0 {
+		end := indexOf(toks, ";")
+		if end < 0 {
+			return nil, fmt.Errorf("line %d: missing ';'", toks[0].line)
+		}
+		stmt := toks[:end]
+		toks = toks[end+1:]
+		if len(stmt) == 0 {
+			continue
+		}
+		switch stmt[0].text {
+		case "data", "end":
+		case "set":
+			err = in.parseSet(stmt[1:])
+		case "param":
+			err = in.parseParam(stmt[1:])
+		default:
+			err = fmt.Errorf("unexpected %q", stmt[0].text)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", stmt[0].line, err)
+		}
+	}
+	in.sortClasses()
+	if err := in.Validate(); err != nil {
+		return nil, err
+	}
+	return in, nil
+}
+
+type token struct {
+	text string
+	line int
+}
+
+func tokenize(s string) ([]token, error) {
+	var toks []token
+	line := 1
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case c == '#':
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(s[i:], "/*"):
+			j := strings.Index(s[i+2:], "*/")
+			if j < 0 {
+				return nil, fmt.Errorf("line %d: unterminated comment", line)
+			}
+			line += strings.Count(s[i:i+2+j], "\n")
+			i += j + 4
+		case strings.HasPrefix(s[i:], ":="):
+			toks = append(toks, token{":=", line})
+			i += 2
+		case strings.ContainsRune("()[],;:", rune(c)):
+			toks = append(toks, token{string(c), line})
+			i++
+		case c == '\'' || c == '"':
+			j := strings.IndexByte(s[i+1:], c)
+			if j < 0 {
+				return nil, fmt.Errorf("line %d: unterminated string", line)
+			}
+			toks = append(toks, token{s[i+1 : i+1+j], line})
+			i += j + 2
+		default:
+			j := i
+			for j < len(s) && !strings.ContainsRune(" \t\r\n()[],;:#'\"", rune(s[j])) {
+				j++
+			}
+			toks = append(toks, token{s[i:j], line})
+			i = j
+		}
+	}
+	return toks, nil
+}
+
+func indexOf(toks []token, text string) int {
+	for i, t := range toks {
+		if t.text == text {
+			return i
+		}
+	}
+	return -1
+}
+
+// header splits "NAME [index] := values" into its parts, dropping tuple
+// punctuation from the index and the values.
+func header(stmt []token) (name string, index, values []string, err error) {
+	if len(stmt) == 0 {
+		return "", nil, nil, fmt.Errorf("missing name")
+	}
+	name = stmt[0].text
+	assign := indexOf(stmt, ":=")
+	if assign < 0 {
+		return "", nil, nil, fmt.Errorf("%s: missing ':='", name)
+	}
+	return name, words(stmt[1:assign]), words(stmt[assign+1:]), nil
+}
+
+func words(toks []token) []string {
+	var w []string
+	for _, t := range toks {
+		switch t.text {
+		case "(", ")", "[", "]", ",":
+		default:
+			w = append(w, t.text)
+		}
+	}
+	return w
+}
+
+func (in *Instance) parseSet(stmt []token) error {
+	name, index, vals, err := header(stmt)
+	if err != nil {
+		return err
+	}
+	switch name {
+	case "NODES":
+		in.Nodes = vals
+	case "LINKS":
+		in.Links, err = links(vals)
+	case "TRAFFIC":
+		in.Traffic, err = demands(vals)
+	case "MODULATIONS":
+		in.Modulations = vals
+	case "ZONES":
+		in.Zones = vals
+	case "CLASSES":
+		in.Classes = vals
+	case "SCENARIOS":
+		in.Scenarios = vals
+	case "PATHS":
+		if len(index) != 2 {
+			return fmt.Errorf("PATHS: want index (s,d)")
+		}
+		t := Demand{index[0], index[1]}
+		in.Paths[t] = vals
+	case "PATH_LINKS":
+		if len(index) != 3 {
+			return fmt.Errorf("PATH_LINKS: want index [(s,d),p]")
+		}
+		k := PathKey{Demand{index[0], index[1]}, index[2]}
+		in.PathLinks[k], err = links(vals)
+	case "FEAS_MOD":
+		if len(index) != 3 {
+			return fmt.Errorf("FEAS_MOD: want index [(s,d),p]")
+		}
+		in.FeasMod[PathKey{Demand{index[0], index[1]}, index[2]}] = vals
+	default:
+		return fmt.Errorf("unknown set %s", name)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+func links(vals []string) ([]Link, error) {
+	if len(vals)%2 != 0 {
+		return nil, fmt.Errorf("odd number of link end points")
+	}
+	ls := make([]Link, 0, len(vals)/2)
+	for i := 0; i < len(vals); i += 2 {
+		ls = append(ls, Link{vals[i], vals[i+1]})
+	}
+	return ls, nil
+}
+
+func demands(vals []string) ([]Demand, error) {
+	if len(vals)%2 != 0 {
+		return nil, fmt.Errorf("odd number of traffic end points")
+	}
+	ds := make([]Demand, 0, len(vals)/2)
+	for i := 0; i < len(vals); i += 2 {
+		ds = append(ds, Demand{vals[i], vals[i+1]})
+	}
+	return ds, nil
+}
+
+func (in *Instance) parseParam(stmt []token) error {
+	name, _, vals, err := header(stmt)
+	if err != nil {
+		return err
+	}
+	// Scalar parameters.
+	scalar := map[string]func(string) error{
+		"C":       func(v string) (err error) { in.C, err = units.ParseDataRate(v); return },
+		"G":       func(v string) (err error) { in.G, err = parseSlots(v); return },
+		"K":       func(v string) (err error) { in.K, err = parseInt(v); return },
+		"N_slots": func(v string) (err error) { in.NSlots, err = parseSlots(v); return },
+		"M_big":   func(v string) (err error) { in.MBig, err = parseInt(v); return },
+		"Gamma":   func(v string) (err error) { in.Gamma, err = parseFloat(v); return },
+	}
+	if set, ok := scalar[name]; ok {
+		if len(vals) != 1 {
+			return fmt.Errorf("%s: want a single value", name)
+		}
+		return set(vals[0])
+	}
+	keyLen := map[string]int{"D": 2, "T_sd": 2, "T_hat": 2, "R": 1, "C_z": 1, "N_req": 4, "Class": 2, "W": 1, "Prob": 1, "T_scen": 3}
+	n, ok := keyLen[name]
+	if !ok {
+		return fmt.Errorf("unknown param %s", name)
+	}
+	if len(vals)%(n+1) != 0 {
+		return fmt.Errorf("%s: want %d-tuple keys followed by a value", name, n)
+	}
+	for i := 0; i < len(vals); i += n + 1 {
+		k, v := vals[i:i+n], vals[i+n]
+		if name == "Class" {
+			in.Class[Demand{k[0], k[1]}] = v
+			continue
+		}
+		if err := in.setParam(name, k, v); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// setParam sets entry k of table name to v, parsed in the unit of the
+// parameter: km for D and R, Gbps for T_sd, T_hat and T_scen, slots for
+// C_z. Bare numbers are in these units, as in data.dat.
+func (in *Instance) setParam(name string, k []string, v string) error {
+	var err error
+	switch name {
+	case "D":
+		in.D[Link{k[0], k[1]}], err = units.ParseDistance(v)
+	case "R":
+		in.R[k[0]], err = units.ParseDistance(v)
+	case "T_sd":
+		in.T[Demand{k[0], k[1]}], err = units.ParseDataRate(v)
+	case "T_hat":
+		in.THat[Demand{k[0], k[1]}], err = units.ParseDataRate(v)
+	case "T_scen":
+		if in.TScen[k[2]] == nil {
+			in.TScen[k[2]] = map[Demand]units.DataRate{}
+		}
+		in.TScen[k[2]][Demand{k[0], k[1]}], err = units.ParseDataRate(v)
+	case "C_z":
+		in.CZ[k[0]], err = parseSlots(v)
+	case "Prob", "W", "N_req":
+		var f float64
+		if f, err = parseFloat(v); err != nil {
+			return err
+		}
+		switch name {
+		case "Prob":
+			in.Prob[k[0]] = f
+		case "W":
+			in.W[k[0]] = f
+		case "N_req":
+			in.NReq[ModKey{Demand{k[0], k[1]}, k[2], k[3]}], err = toInt(f)
+		}
+	}
+	return err
+}
+
+func parseSlots(s string) (int, error) {
+	n, err := units.ParseSlots(s)
+	return int(n), err
+}
+
+func parseFloat(s string) (float64, error) {
+	return strconv.ParseFloat(s, 64)
+}
+
+func parseInt(s string) (int, error) {
+	f, err := parseFloat(s)
+	if err != nil {
+		return 0, err
+	}
+	return toInt(f)
+}
+
+func toInt(f float64) (int, error) {
+	if f != math.Trunc(f) {
+		return 0, fmt.Errorf("%v is not an integer", f)
+	}
+	return int(f), nil
+}
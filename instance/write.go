@@ -0,0 +1,33 @@
+package instance
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WriteTopology writes NODES, LINKS and D in the layout of data.dat, as a
+// data section that the traffic and spectrum parameters can follow.
+func (in *Instance) WriteTopology(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "/* Topology: Nodes and Links */\nset NODES := %s;\n\n", strings.Join(in.Nodes, " "))
+	fmt.Fprint(bw, "/* Define LINKS as unordered pairs (assumed undirected) */\nset LINKS :=\n    ")
+	for _, l := range in.Links {
+		fmt.Fprintf(bw, " (%s,%s)", l.I, l.J)
+	}
+	fmt.Fprint(bw, ";\n\n/* Link distances */\nparam D :=")
+	for i, l := range in.Links {
+		sep := ","
+		if i == len(in.Links)-1 {
+			sep = ";"
+		}
+		fmt.Fprintf(bw, "\n   [%s,%s] %s%s", l.I, l.J, strconv.FormatFloat(float64(in.D[l]), 'g', -1, 64), sep)
+	}
+	if len(in.Links) == 0 {
+		fmt.Fprint(bw, ";")
+	}
+	fmt.Fprintln(bw)
+	return bw.Flush()
+}
@@ -0,0 +1,309 @@
+// Package markov computes exact blocking probabilities of the simulator's
+// allocation policies on tiny instances. Under Poisson arrivals and
+// exponential holding times the spectrum state is a continuous-time
+// Markov chain: this package enumerates the states reachable from the
+// empty network under a policy, solves the chain for its stationary
+// distribution and, as arrivals see time averages, reads the blocking of
+// every demand off the states in which the policy finds no placement for
+// it.
+package markov
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+	"github.com/dilwar-crnlab/hpsr_2025/sim"
+)
+
+// lightpath is an established block: the traffic index, the option of
+// the demand and the first slot. Equal lightpaths would overlap, so a
+// state never holds two.
+type lightpath struct{ t, opt, start int }
+
+// state is a set of lightpaths in increasing order.
+type state []lightpath
+
+func (s state) key() string {
+	var b strings.Builder
+	for _, lp := range s {
+		b.WriteString(strconv.Itoa(lp.t))
+		b.WriteByte('/')
+		b.WriteString(strconv.Itoa(lp.opt))
+		b.WriteByte('/')
+		b.WriteString(strconv.Itoa(lp.start))
+		b.WriteByte(' ')
+	}
+	return b.String()
+}
+
+func (s state) with(lp lightpath) state {
+	n := append(append(state(nil), s...), lp)
+	sort.Slice(n, func(a, b int) bool {
+		x, y := n[a], n[b]
+		if x.t != y.t {
+			return x.t < y.t
+		}
+		if x.opt != y.opt {
+			return x.opt < y.opt
+		}
+		return x.start < y.start
+	})
+	return n
+}
+
+func (s state) without(i int) state {
+	return append(append(state(nil), s[:i]...), s[i+1:]...)
+}
+
+// edge is a transition into a state.
+type edge struct {
+	from int
+	rate float64
+}
+
+// Chain is the Markov chain of the spectrum states of a policy.
+type Chain struct {
+	Policy  string
+	Demands []instance.Demand
+	Rate    []float64 // arrival rate per traffic index
+	Holding float64   // mean holding time
+
+	states  []state
+	blocked [][]bool  // per state and traffic index
+	out     []float64 // total rate out of each state
+	into    [][]edge  // per state
+}
+
+// States is the number of states of c.
+func (c *Chain) States() int { return len(c.states) }
+
+// Transitions is the number of transitions between distinct states of c.
+func (c *Chain) Transitions() int {
+	n := 0
+	for _, es := range c.into {
+		n += len(es)
+	}
+	return n
+}
+
+// Build enumerates the states policy reaches on in at the offered load
+// and mean holding time of cfg, which shares the load among the demands in
+// proportion to T_sd as the simulator does. It fails once more than
+// maxStates states are found. Only the allocator's placement is modelled,
+// so cfg must not enable preemption, admission control, crankback or
+// batching, and time-aware allocation, which looks at departure times, is
+// not Markovian in the spectrum state.
+func Build(in *instance.Instance, policy string, cfg sim.Config, maxStates int) (*Chain, error) {
+	switch {
+	case cfg.Preempt != nil, cfg.Admission != nil, len(cfg.Crankback) > 0, cfg.Batch != nil:
+		return nil, errors.New("markov: only plain allocation is modelled")
+	case cfg.Load <= 0 || cfg.Holding <= 0:
+		return nil, fmt.Errorf("markov: load %g and holding time %g must be positive", cfg.Load, cfg.Holding)
+	}
+	n := sim.NewNetwork(in)
+	alloc, err := sim.NewAllocator(policy, n, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := alloc.(*sim.TimeAware); ok {
+		return nil, fmt.Errorf("markov: policy %s depends on holding times", alloc.Name())
+	}
+	c := &Chain{Policy: alloc.Name(), Demands: in.Traffic, Holding: cfg.Holding}
+	var sum float64
+	for _, t := range in.Traffic {
+		sum += float64(in.T[t])
+	}
+	if sum == 0 {
+		return nil, errors.New("markov: no traffic")
+	}
+	for _, t := range in.Traffic {
+		c.Rate = append(c.Rate, cfg.Load/cfg.Holding*float64(in.T[t])/sum)
+	}
+
+	index := map[string]int{}
+	add := func(s state) (int, error) {
+		k := s.key()
+		if i, ok := index[k]; ok {
+			return i, nil
+		}
+		if len(c.states) == maxStates {
+			return 0, fmt.Errorf("markov: more than %d states", maxStates)
+		}
+		index[k] = len(c.states)
+		c.states = append(c.states, s)
+		c.into = append(c.into, nil)
+		return len(c.states) - 1, nil
+	}
+	if _, err := add(nil); err != nil {
+		return nil, err
+	}
+	// States are appended as they are found, so the loop visits them
+	// breadth-first.
+	for i := 0; i < len(c.states); i++ {
+		s := c.states[i]
+		lps := make([]*sim.Lightpath, len(s))
+		for j, lp := range s {
+			w := n.Width(n.Options[lp.t][lp.opt])
+			lps[j] = n.Establish(&sim.Request{ID: j + 1, T: lp.t}, sim.Placement{Opt: lp.opt, Start: lp.start, Width: w})
+		}
+		blocked := make([]bool, len(in.Traffic))
+		var out float64
+		var next []edge // transitions out of s, with to in from
+		for t := range in.Traffic {
+			pl, ok := alloc.Place(n, &sim.Request{ID: len(s) + 1, T: t})
+			if !ok {
+				blocked[t] = true
+				continue
+			}
+			to, err := add(s.with(lightpath{t, pl.Opt, pl.Start}))
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, edge{to, c.Rate[t]})
+		}
+		for j := range s {
+			to, err := add(s.without(j))
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, edge{to, 1 / cfg.Holding})
+		}
+		for _, lp := range lps {
+			n.Teardown(lp)
+		}
+		for _, e := range next {
+			if e.rate > 0 {
+				c.into[e.from] = append(c.into[e.from], edge{i, e.rate})
+				out += e.rate
+			}
+		}
+		c.blocked = append(c.blocked, blocked)
+		c.out = append(c.out, out)
+	}
+	return c, nil
+}
+
+// Result is the exact blocking of a chain.
+type Result struct {
+	Policy              string
+	Demands             []instance.Demand
+	States, Transitions int
+	Sweeps              int
+	Converged           bool
+	Blocking            []float64 // per traffic index
+	Total               float64   // blocking of an arbitrary arrival
+	Occupancy           float64   // mean number of established lightpaths
+
+	// Simulated blocking per traffic index and in total, set by Simulate.
+	Sim      []sim.Estimate
+	SimTotal sim.Estimate
+}
+
+// Solve computes the stationary distribution of c by Gauss-Seidel sweeps
+// over the balance equations, from the uniform distribution until no
+// probability changes by more than tol relative to its value, or for at
+// most maxSweeps sweeps.
+func (c *Chain) Solve(tol float64, maxSweeps int) *Result {
+	pi := make([]float64, len(c.states))
+	for i := range pi {
+		pi[i] = 1 / float64(len(pi))
+	}
+	res := &Result{Policy: c.Policy, Demands: c.Demands, States: c.States(), Transitions: c.Transitions()}
+	for res.Sweeps < maxSweeps {
+		res.Sweeps++
+		change := 0.0
+		for j, es := range c.into {
+			if c.out[j] == 0 {
+				continue
+			}
+			var x float64
+			for _, e := range es {
+				x += pi[e.from] * e.rate
+			}
+			x /= c.out[j]
+			if x > 0 {
+				change = max(change, math.Abs(x-pi[j])/x)
+			}
+			pi[j] = x
+		}
+		var sum float64
+		for _, p := range pi {
+			sum += p
+		}
+		for i := range pi {
+			pi[i] /= sum
+		}
+		if change < tol {
+			res.Converged = true
+			break
+		}
+	}
+	res.Blocking = make([]float64, len(c.Demands))
+	for i, p := range pi {
+		for t, b := range c.blocked[i] {
+			if b {
+				res.Blocking[t] += p
+			}
+		}
+		res.Occupancy += p * float64(len(c.states[i]))
+	}
+	var rate, lost float64
+	for t, r := range c.Rate {
+		rate += r
+		lost += r * res.Blocking[t]
+	}
+	if rate > 0 {
+		res.Total = lost / rate
+	}
+	return res
+}
+
+// Simulate estimates the blocking of every demand of r by simulating its
+// policy on in with sim.DemandBlocking, to cross-check the simulator.
+func (r *Result) Simulate(in *instance.Instance, cfg sim.Config, reps int) error {
+	var err error
+	r.Sim, r.SimTotal, err = sim.DemandBlocking(in, r.Policy, cfg, reps)
+	return err
+}
+
+// Write prints the exact blocking of every demand of r, next to the
+// simulated one when Simulate was called.
+func (r *Result) Write(w io.Writer) error {
+	status := "converged"
+	if !r.Converged {
+		status = "not converged"
+	}
+	if _, err := fmt.Fprintf(w, "policy %s, %d states, %d transitions, %d sweeps (%s), mean %.4g lightpaths\n",
+		r.Policy, r.States, r.Transitions, r.Sweeps, status, r.Occupancy); err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	head := "demand\texact\t"
+	if r.Sim != nil {
+		head += "simulated\t"
+	}
+	fmt.Fprintln(tw, head)
+	row := func(name string, exact float64, e sim.Estimate) {
+		fmt.Fprintf(tw, "%s\t%.4e\t", name, exact)
+		if r.Sim != nil {
+			fmt.Fprintf(tw, "%v\t", e)
+		}
+		fmt.Fprintln(tw)
+	}
+	for t, d := range r.Demands {
+		var e sim.Estimate
+		if r.Sim != nil {
+			e = r.Sim[t]
+		}
+		row(d.String(), r.Blocking[t], e)
+	}
+	row("total", r.Total, r.SimTotal)
+	return tw.Flush()
+}
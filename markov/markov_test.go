@@ -0,0 +1,64 @@
+package markov
+
+import (
+	"math"
+	"testing"
+
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+	"github.com/dilwar-crnlab/hpsr_2025/sim"
+)
+
+// solve builds and solves the chain of policy on testdata/data with its
+// zones resized to slots.
+func solve(t *testing.T, data string, slots int, policy string, cfg sim.Config) (*instance.Instance, *Result) {
+	t.Helper()
+	in, err := instance.Load("../testdata/" + data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	in.ScaleZones(slots)
+	c, err := Build(in, policy, cfg, 100000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := c.Solve(1e-12, 100000)
+	if !r.Converged {
+		t.Fatalf("not converged after %d sweeps", r.Sweeps)
+	}
+	return in, r
+}
+
+func TestErlangB(t *testing.T) {
+	// E(2,1) = (1/2) / (1 + 1 + 1/2).
+	_, r := solve(t, "single.dat", 2, "first-fit", sim.Config{Load: 1, Holding: 1})
+	// Empty, slot 0, slot 1 and full.
+	if r.States != 4 {
+		t.Errorf("%d states, want 4", r.States)
+	}
+	if math.Abs(r.Total-0.2) > 1e-9 {
+		t.Errorf("blocking %.9g, want 0.2", r.Total)
+	}
+	if math.Abs(r.Occupancy-0.8) > 1e-9 {
+		t.Errorf("occupancy %.9g, want 0.8", r.Occupancy)
+	}
+}
+
+func TestSimulator(t *testing.T) {
+	for _, policy := range []string{"zone-flf", "first-fit"} {
+		t.Run(policy, func(t *testing.T) {
+			cfg := sim.Config{Load: 2, Holding: 1, Arrivals: 20000, Warmup: 1000, Seed: 1}
+			in, r := solve(t, "line3.dat", 6, policy, cfg)
+			if err := r.Simulate(in, cfg, 5); err != nil {
+				t.Fatal(err)
+			}
+			for ti, d := range r.Demands {
+				if e := r.Sim[ti]; math.Abs(r.Blocking[ti]-e.Mean) > 2*e.HalfWidth {
+					t.Errorf("%v: exact %.4e, simulated %v", d, r.Blocking[ti], e)
+				}
+			}
+			if e := r.SimTotal; math.Abs(r.Total-e.Mean) > 2*e.HalfWidth {
+				t.Errorf("total: exact %.4e, simulated %v", r.Total, e)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package milp
+
+import (
+	"math"
+	"time"
+
+	"github.com/dilwar-crnlab/hpsr_2025/model"
+)
+
+// Options limits branch and bound.
+type Options struct {
+	NodeLimit int       // <= 0 for no limit
+	Deadline  time.Time // zero for no limit
+	// Incumbent, if not nil, is a known feasible solution to start from.
+	Incumbent []float64
+}
+
+// Result is the outcome of branch and bound.
+type Result struct {
+	Status Status
+	X      []float64 // best integer solution found
+	Obj    float64   // its objective
+	Bound  float64   // upper bound on the optimum
+	Root   float64   // LP relaxation bound at the root
+	Nodes  int
+	Iters  int
+}
+
+// Gap is the relative gap between Obj and Bound.
+func (r Result) Gap() float64 {
+	if r.X == nil {
+		return math.Inf(1)
+	}
+	return (r.Bound - r.Obj) / math.Max(1, math.Abs(r.Obj))
+}
+
+type node struct {
+	lo, hi []float64
+	bound  float64 // LP bound of the parent
+}
+
+const intTol = 1e-6
+
+// Solve maximises p by depth-first branch and bound on the most
+// fractional integer variable, exploring first the branch its LP value is
+// closer to.
+func Solve(p *model.MILP, opt Options) Result {
+	n := len(p.Vars)
+	lo := make([]float64, n)
+	hi := make([]float64, n)
+	for j, v := range p.Vars {
+		lo[j], hi[j] = v.Lo, v.Hi
+		if v.Kind != model.Continuous {
+			lo[j], hi[j] = math.Ceil(v.Lo-intTol), math.Floor(v.Hi+intTol)
+		}
+	}
+	res := Result{Status: Infeasible, Obj: math.Inf(-1), Bound: math.Inf(1), Root: math.Inf(1)}
+	if opt.Incumbent != nil && p.Feasible(opt.Incumbent, intTol) == nil {
+		res.X, res.Obj = append([]float64(nil), opt.Incumbent...), p.Value(opt.Incumbent)
+	}
+	stack := []node{{lo, hi, math.Inf(1)}}
+	stopped := false
+	// unresolved is the best parent bound of the nodes whose LP stopped
+	// at a limit; they stay open.
+	unresolved := math.Inf(-1)
+	for len(stack) > 0 {
+		if opt.NodeLimit > 0 && res.Nodes >= opt.NodeLimit ||
+			!opt.Deadline.IsZero() && time.Now().After(opt.Deadline) {
+			stopped = true
+			break
+		}
+		nd := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if nd.bound <= res.Obj+intTol {
+			continue
+		}
+		res.Nodes++
+		lp := SolveLP(p, nd.lo, nd.hi)
+		res.Iters += lp.Iters
+		if res.Nodes == 1 {
+			switch lp.Status {
+			case Optimal:
+				res.Root = lp.Obj
+			case Unbounded:
+				res.Status = Unbounded
+				return res
+			}
+		}
+		if lp.Status != Optimal && lp.Status != Infeasible {
+			stopped = true
+			unresolved = math.Max(unresolved, nd.bound)
+			continue
+		}
+		if lp.Status != Optimal || lp.Obj <= res.Obj+intTol {
+			continue
+		}
+		j, frac := -1, 0.0
+		for k, v := range p.Vars {
+			if v.Kind == model.Continuous {
+				continue
+			}
+			f := lp.X[k] - math.Floor(lp.X[k])
+			if f > intTol && f < 1-intTol {
+				if d := math.Min(f, 1-f); d > frac {
+					j, frac = k, d
+				}
+			}
+		}
+		if j < 0 {
+			x := lp.X
+			for k, v := range p.Vars {
+				if v.Kind != model.Continuous {
+					x[k] = math.Round(x[k])
+				}
+			}
+			res.X, res.Obj = x, lp.Obj
+			continue
+		}
+		down := node{nd.lo, append([]float64(nil), nd.hi...), lp.Obj}
+		down.hi[j] = math.Floor(lp.X[j])
+		up := node{append([]float64(nil), nd.lo...), nd.hi, lp.Obj}
+		up.lo[j] = math.Ceil(lp.X[j])
+		if lp.X[j]-math.Floor(lp.X[j]) >= 0.5 {
+			stack = append(stack, down, up)
+		} else {
+			stack = append(stack, up, down)
+		}
+	}
+	// The bound is the best of the incumbent and the open nodes.
+	res.Bound = math.Max(res.Obj, unresolved)
+	for _, nd := range stack {
+		res.Bound = math.Max(res.Bound, nd.bound)
+	}
+	switch {
+	case res.X != nil && !stopped:
+		res.Status = Optimal
+	case res.X != nil:
+		res.Status = Feasible
+	case stopped:
+		res.Status = Limit
+		res.Bound = math.Max(res.Bound, res.Root)
+	}
+	return res
+}
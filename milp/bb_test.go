@@ -0,0 +1,67 @@
+package milp
+
+import (
+	"math"
+	"testing"
+
+	"github.com/dilwar-crnlab/hpsr_2025/model"
+)
+
+// knapsack is max 5a + 4b + 3c subject to 2a + 3b + c <= 5 over binaries,
+// whose LP relaxation (a = c = 1, b = 2/3) is fractional and whose
+// optimum is a = b = 1.
+func knapsack() *model.MILP {
+	m := model.NewMILP("knapsack")
+	a := m.AddVar("a", model.Binary, 0, 1)
+	b := m.AddVar("b", model.Binary, 0, 1)
+	c := m.AddVar("c", model.Binary, 0, 1)
+	m.Maximize(model.Term{Var: a, Coef: 5}, model.Term{Var: b, Coef: 4}, model.Term{Var: c, Coef: 3})
+	m.AddRow("cap", []model.Term{{Var: a, Coef: 2}, {Var: b, Coef: 3}, {Var: c, Coef: 1}}, model.LE, 5)
+	return m
+}
+
+// odd asks for an integer x with 2x = 3, which only the relaxation has.
+func odd() *model.MILP {
+	m := model.NewMILP("odd")
+	x := m.AddVar("x", model.Integer, 0, 3)
+	m.Maximize(model.Term{Var: x, Coef: 1})
+	m.AddRow("half", []model.Term{{Var: x, Coef: 2}}, model.EQ, 3)
+	return m
+}
+
+func TestSolve(t *testing.T) {
+	tests := []struct {
+		name   string
+		p      *model.MILP
+		opt    Options
+		status Status
+		obj    float64
+	}{
+		{"knapsack", knapsack(), Options{}, Optimal, 9},
+		{"knapsack from incumbent", knapsack(), Options{Incumbent: []float64{1, 0, 1}}, Optimal, 9},
+		{"odd", odd(), Options{}, Infeasible, 0},
+		// The root LP is fractional: a single node can prove nothing.
+		{"knapsack, one node", knapsack(), Options{NodeLimit: 1}, Limit, 0},
+		{"knapsack from incumbent, one node", knapsack(), Options{NodeLimit: 1, Incumbent: []float64{1, 0, 1}}, Feasible, 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Solve(tt.p, tt.opt)
+			if r.Status != tt.status {
+				t.Fatalf("status %v, want %v", r.Status, tt.status)
+			}
+			if tt.status != Infeasible && r.Bound < 9-1e-6 {
+				t.Errorf("bound %g below the optimum 9 of the knapsack", r.Bound)
+			}
+			if r.X == nil {
+				return
+			}
+			if err := tt.p.Feasible(r.X, 1e-6); err != nil {
+				t.Errorf("solution infeasible: %v", err)
+			}
+			if math.Abs(r.Obj-tt.obj) > 1e-6 {
+				t.Errorf("objective %g, want %g", r.Obj, tt.obj)
+			}
+		})
+	}
+}
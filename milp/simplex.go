@@ -0,0 +1,331 @@
+// Package milp solves the MILPs of package model with a dense bounded
+// primal simplex method and depth-first branch and bound. It is meant for
+// the small instances used to validate formulations and heuristics, not as
+// a replacement for glpsol on large ones.
+package milp
+
+import (
+	"math"
+
+	"github.com/dilwar-crnlab/hpsr_2025/model"
+)
+
+// Status is the outcome of a solve.
+type Status int
+
+const (
+	Optimal Status = iota
+	Feasible
+	Infeasible
+	Unbounded
+	Limit // iteration, node or time limit reached without a solution
+)
+
+func (s Status) String() string {
+	return [...]string{"optimal", "feasible", "infeasible", "unbounded", "limit"}[s]
+}
+
+const (
+	feasTol  = 1e-9
+	costTol  = 1e-9
+	pivotTol = 1e-9
+)
+
+// LP is the result of solving a linear relaxation.
+type LP struct {
+	Status Status
+	Obj    float64 // of the maximisation
+	X      []float64
+	Iters  int
+}
+
+// tableau is a dense simplex tableau over the structural, slack and
+// artificial columns of a program in the form A x + s (+ art) = b.
+type tableau struct {
+	m, n   int // rows, structural columns
+	cols   int
+	t      [][]float64
+	x      []float64 // value of every column
+	lo, hi []float64
+	basis  []int // column basic in each row
+	basic  []bool
+	d      []float64 // reduced costs
+}
+
+// SolveLP solves the linear relaxation of p with the variable bounds
+// replaced by lo and hi.
+func SolveLP(p *model.MILP, lo, hi []float64) LP {
+	tb := newTableau(p, lo, hi)
+	if tb == nil {
+		return LP{Status: Infeasible}
+	}
+	iters := 0
+	// Phase 1: drive the artificial columns to zero.
+	art := make([]float64, tb.cols)
+	for j := tb.n + tb.m; j < tb.cols; j++ {
+		art[j] = 1
+	}
+	st, it := tb.run(art)
+	iters += it
+	if st != Optimal {
+		return LP{Status: Limit, Iters: iters}
+	}
+	sum := 0.0
+	for j := tb.n + tb.m; j < tb.cols; j++ {
+		sum += tb.x[j]
+	}
+	if sum > 1e-7 {
+		return LP{Status: Infeasible, Iters: iters}
+	}
+	for j := tb.n + tb.m; j < tb.cols; j++ {
+		tb.hi[j] = 0
+	}
+	// Phase 2: minimise the negated objective.
+	c := make([]float64, tb.cols)
+	for _, t := range p.Obj {
+		c[t.Var] = -t.Coef
+	}
+	st, it = tb.run(c)
+	iters += it
+	if st != Optimal {
+		return LP{Status: st, Iters: iters}
+	}
+	x := append([]float64(nil), tb.x[:tb.n]...)
+	return LP{Status: Optimal, Obj: p.Value(x), X: x, Iters: iters}
+}
+
+func newTableau(p *model.MILP, lo, hi []float64) *tableau {
+	n, m := len(p.Vars), len(p.Rows)
+	tb := &tableau{m: m, n: n}
+	for j := 0; j < n; j++ {
+		if lo[j] > hi[j]+feasTol {
+			return nil
+		}
+	}
+	// Nonbasic structural columns start at a finite bound.
+	xs := make([]float64, n)
+	for j := range xs {
+		switch {
+		case !math.IsInf(lo[j], -1):
+			xs[j] = lo[j]
+		case !math.IsInf(hi[j], 1):
+			xs[j] = hi[j]
+		}
+	}
+	// Rows whose slack cannot absorb the residual get an artificial column.
+	resid := make([]float64, m)
+	var arts []int
+	for i, r := range p.Rows {
+		v := r.RHS
+		for _, t := range r.Terms {
+			v -= t.Coef * xs[t.Var]
+		}
+		resid[i] = v
+		ok := r.Sense == LE && v >= -feasTol || r.Sense == GE && v <= feasTol || r.Sense == EQ && math.Abs(v) <= feasTol
+		if !ok {
+			arts = append(arts, i)
+		}
+	}
+	tb.cols = n + m + len(arts)
+	tb.t = make([][]float64, m)
+	tb.x = make([]float64, tb.cols)
+	tb.lo = make([]float64, tb.cols)
+	tb.hi = make([]float64, tb.cols)
+	tb.basis = make([]int, m)
+	tb.basic = make([]bool, tb.cols)
+	copy(tb.x, xs)
+	copy(tb.lo, lo)
+	copy(tb.hi, hi)
+	for i, r := range p.Rows {
+		row := make([]float64, tb.cols)
+		for _, t := range r.Terms {
+			row[t.Var] = t.Coef
+		}
+		row[n+i] = 1
+		tb.t[i] = row
+		s := n + i
+		switch r.Sense {
+		case LE:
+			tb.lo[s], tb.hi[s] = 0, math.Inf(1)
+		case GE:
+			tb.lo[s], tb.hi[s] = math.Inf(-1), 0
+		case EQ:
+			tb.lo[s], tb.hi[s] = 0, 0
+		}
+		tb.basis[i] = s
+	}
+	for k, i := range arts {
+		a := n + m + k
+		sigma := 1.0
+		if resid[i] < 0 {
+			sigma = -1
+		}
+		tb.t[i][a] = sigma
+		tb.lo[a], tb.hi[a] = 0, math.Inf(1)
+		// Make the artificial column basic: scale the row by sigma.
+		for j := range tb.t[i] {
+			tb.t[i][j] *= sigma
+		}
+		tb.basis[i] = a
+	}
+	for i := 0; i < m; i++ {
+		bj := tb.basis[i]
+		tb.basic[bj] = true
+		if bj >= n+m {
+			tb.x[bj] = math.Abs(resid[i])
+		} else {
+			tb.x[bj] = resid[i]
+		}
+	}
+	return tb
+}
+
+// LE, GE and EQ shorten the row senses.
+const (
+	LE = model.LE
+	GE = model.GE
+	EQ = model.EQ
+)
+
+// run minimises cost from the current basic feasible solution.
+func (tb *tableau) run(cost []float64) (Status, int) {
+	tb.d = make([]float64, tb.cols)
+	copy(tb.d, cost)
+	for i, bj := range tb.basis {
+		if cb := cost[bj]; cb != 0 {
+			for j, v := range tb.t[i] {
+				tb.d[j] -= cb * v
+			}
+		}
+	}
+	limit := 50 * (tb.m + tb.cols)
+	degenerate := 0
+	for it := 0; it < limit; it++ {
+		j, dir := tb.entering(degenerate > 50)
+		if j < 0 {
+			return Optimal, it
+		}
+		r, theta := tb.ratio(j, dir, degenerate > 50)
+		if math.IsInf(theta, 1) {
+			return Unbounded, it
+		}
+		if theta < feasTol {
+			degenerate++
+		} else {
+			degenerate = 0
+		}
+		for i, bj := range tb.basis {
+			tb.x[bj] -= dir * theta * tb.t[i][j]
+		}
+		tb.x[j] += dir * theta
+		if r >= 0 {
+			tb.pivot(r, j)
+		}
+	}
+	return Limit, limit
+}
+
+// entering picks a nonbasic column whose move improves the cost, by the
+// largest reduced cost or, to escape cycling, the lowest index.
+func (tb *tableau) entering(bland bool) (int, float64) {
+	best, dir, bestD := -1, 0.0, 0.0
+	for j := 0; j < tb.cols; j++ {
+		if tb.basic[j] || tb.hi[j]-tb.lo[j] <= feasTol {
+			continue
+		}
+		dj := tb.d[j]
+		var dd float64
+		switch {
+		case dj < -costTol && tb.x[j] < tb.hi[j]-feasTol:
+			dd = 1
+		case dj > costTol && tb.x[j] > tb.lo[j]+feasTol:
+			dd = -1
+		default:
+			continue
+		}
+		if bland {
+			return j, dd
+		}
+		if math.Abs(dj) > bestD {
+			best, dir, bestD = j, dd, math.Abs(dj)
+		}
+	}
+	return best, dir
+}
+
+// ratio returns the row leaving the basis when column j moves in direction
+// dir, or -1 when j reaches its own opposite bound first, and the step.
+func (tb *tableau) ratio(j int, dir float64, bland bool) (int, float64) {
+	row, theta := -1, tb.hi[j]-tb.lo[j]
+	bestPivot := 0.0
+	for i, bj := range tb.basis {
+		a := tb.t[i][j]
+		if math.Abs(a) <= pivotTol {
+			continue
+		}
+		rate := -dir * a // change of x[bj] per unit step
+		var step float64
+		switch {
+		case rate < 0 && !math.IsInf(tb.lo[bj], -1):
+			step = (tb.x[bj] - tb.lo[bj]) / -rate
+		case rate > 0 && !math.IsInf(tb.hi[bj], 1):
+			step = (tb.hi[bj] - tb.x[bj]) / rate
+		default:
+			continue
+		}
+		step = math.Max(step, 0)
+		better := step < theta-feasTol
+		if !better && step <= theta+feasTol && row >= 0 {
+			if bland {
+				better = bj < tb.basis[row]
+			} else {
+				better = math.Abs(a) > bestPivot
+			}
+		}
+		if better {
+			row, theta, bestPivot = i, step, math.Abs(a)
+		}
+	}
+	return row, theta
+}
+
+// pivot makes column j basic in row r.
+func (tb *tableau) pivot(r, j int) {
+	leaving := tb.basis[r]
+	// Snap the leaving column onto the bound it reached.
+	if math.Abs(tb.x[leaving]-tb.lo[leaving]) < math.Abs(tb.x[leaving]-tb.hi[leaving]) {
+		tb.x[leaving] = tb.lo[leaving]
+	} else {
+		tb.x[leaving] = tb.hi[leaving]
+	}
+	pr := tb.t[r]
+	inv := 1 / pr[j]
+	for k := range pr {
+		pr[k] *= inv
+	}
+	pr[j] = 1
+	for i, row := range tb.t {
+		if i == r {
+			continue
+		}
+		if f := row[j]; f != 0 {
+			for k, v := range pr {
+				if v != 0 {
+					row[k] -= f * v
+				}
+			}
+			row[j] = 0
+		}
+	}
+	if f := tb.d[j]; f != 0 {
+		for k, v := range pr {
+			if v != 0 {
+				tb.d[k] -= f * v
+			}
+		}
+		tb.d[j] = 0
+	}
+	tb.basic[leaving] = false
+	tb.basic[j] = true
+	tb.basis[r] = j
+}
@@ -0,0 +1,174 @@
+package model
+
+import (
+	"math"
+
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+	"github.com/dilwar-crnlab/hpsr_2025/plan"
+)
+
+// BigM generates the disjunctive formulation of ilp.mod: integer
+// StartSlot/EndSlot per request and, per pair t1 < t2, a binary y[t1,t2]
+// ordering the two blocks through big-M constraints.
+//
+// It differs from ilp.mod where the model file is simplified:
+//   - only reach-feasible (p,m) get a UseMod variable, replacing
+//     ComputePathDist and ModulationFeas, and the unused Route, PathDist
+//     and LeftAlloc/RightAlloc variables are left out;
+//   - the block length is EndSlot - StartSlot + 1 = sum N_req UseMod over
+//     all (p,m) of t, so rejected requests get an empty block (EndSlot =
+//     StartSlot - 1) and take no part in the non-overlap constraints;
+//   - with opt.PerLink the non-overlap of a pair is enforced only when the
+//     chosen paths share a link, and with opt.Zones a zone binary u[t,z]
+//     confines each block to one zone.
+//
+// Slots are 1-based as in ilp.mod; M is N_slots + G.
+func BigM(in *instance.Instance, opt Options) *Formulation {
+	m := NewMILP("bigm")
+	big := float64(in.NSlots + in.G)
+	ns := float64(in.NSlots)
+	type reqVars struct {
+		t          instance.Demand
+		accept     int
+		start, end int
+		routes     []plan.Route // in plan.Candidates order
+		usePath    map[string]int
+		useMod     map[plan.Route]int
+	}
+	var reqs []*reqVars
+	for _, t := range in.Traffic {
+		rs := plan.Candidates(in, t)
+		if len(rs) == 0 {
+			continue
+		}
+		r := &reqVars{t: t, routes: rs, usePath: map[string]int{}, useMod: map[plan.Route]int{}}
+		r.accept = m.AddVar(name("Accept", t), Binary, 0, 1)
+		r.start = m.AddVar(name("StartSlot", t), Integer, 1, ns+1)
+		r.end = m.AddVar(name("EndSlot", t), Integer, 0, ns)
+		for _, p := range in.Paths[t] {
+			for _, rt := range rs {
+				if rt.P != p {
+					continue
+				}
+				if _, ok := r.usePath[p]; !ok {
+					r.usePath[p] = m.AddVar(name("UsePath", t, p), Binary, 0, 1)
+				}
+				r.useMod[rt] = m.AddVar(name("UseMod", t, p, rt.M), Binary, 0, 1)
+			}
+		}
+		reqs = append(reqs, r)
+	}
+	smax := m.AddVar("S_max", Integer, 0, ns)
+	var usage []Term
+
+	for _, r := range reqs {
+		m.Maximize(Term{r.accept, in.Weight(r.t)})
+
+		// (1) PathSelection and (2) ModulationSelection.
+		terms := []Term{{r.accept, -1}}
+		for _, p := range in.Paths[r.t] {
+			up, ok := r.usePath[p]
+			if !ok {
+				continue
+			}
+			terms = append(terms, Term{up, 1})
+			mods := []Term{{up, -1}}
+			for _, rt := range r.routes {
+				if rt.P == p {
+					mods = append(mods, Term{r.useMod[rt], 1})
+				}
+			}
+			m.AddRow(name("ModulationSelection", r.t, p), mods, EQ, 0)
+		}
+		m.AddRow(name("PathSelection", r.t), terms, EQ, 0)
+
+		// (6) SlotBlockLength.
+		length := []Term{{r.end, 1}, {r.start, -1}}
+		for _, rt := range r.routes {
+			um := r.useMod[rt]
+			length = append(length, Term{um, -float64(plan.Slots(in, r.t, rt))})
+			usage = append(usage, Term{um, float64(plan.Slots(in, r.t, rt) * len(plan.Links(in, r.t, rt)))})
+		}
+		m.AddRow(name("SlotBlockLength", r.t), length, EQ, -1)
+
+		// (8) MaxSpectrum.
+		m.AddRow(name("MaxSpectrum", r.t), []Term{{smax, 1}, {r.end, -1}}, GE, 0)
+
+		if opt.Zones {
+			zs := zoneRanges(in, opt)
+			sel := []Term{{r.accept, -1}}
+			lo := []Term{{r.start, 1}}
+			hi := []Term{{r.end, 1}, {r.accept, ns}}
+			for _, z := range zs {
+				u := m.AddVar(name("InZone", r.t, z.Name), Binary, 0, 1)
+				sel = append(sel, Term{u, 1})
+				lo = append(lo, Term{u, -float64(z.Lo + 1)})
+				hi = append(hi, Term{u, -float64(z.Hi)})
+			}
+			m.AddRow(name("ZoneSelection", r.t), sel, EQ, 0)
+			m.AddRow(name("ZoneStart", r.t), lo, GE, 0)
+			m.AddRow(name("ZoneEnd", r.t), hi, LE, ns)
+		}
+	}
+
+	// (7) SpectrumNonOverlap, relaxed unless both requests are accepted
+	// (or, per link, unless their chosen paths share a link).
+	for i, a := range reqs {
+		for _, b := range reqs[i+1:] {
+			var relax []Term // each v adds big (1 - v) to the right-hand side
+			if opt.PerLink {
+				var pairs [][2]int
+				// Paths in PATHS order, so that rows come out the same on
+				// every run.
+				for _, pa := range in.Paths[a.t] {
+					ua, ok := a.usePath[pa]
+					if !ok {
+						continue
+					}
+					for _, pb := range in.Paths[b.t] {
+						ub, ok := b.usePath[pb]
+						if ok && conflicting(in, a.t, pa, b.t, pb) {
+							pairs = append(pairs, [2]int{ua, ub})
+						}
+					}
+				}
+				if len(pairs) == 0 {
+					continue
+				}
+				z := m.AddVar(name("Share", a.t, b.t), Continuous, 0, 1)
+				for _, pr := range pairs {
+					m.AddRow(name("ShareLink", a.t, b.t, m.Vars[pr[0]].Name, m.Vars[pr[1]].Name),
+						[]Term{{z, 1}, {pr[0], -1}, {pr[1], -1}}, GE, -1)
+				}
+				relax = []Term{{z, big}}
+			} else {
+				relax = []Term{{a.accept, big}, {b.accept, big}}
+			}
+			y := m.AddVar(name("y", a.t, b.t), Binary, 0, 1)
+			// EndSlot[a] + 1 + G <= StartSlot[b] + big (1-y) + big sum(1-relax)
+			rhs := -1 - float64(in.G) + big*float64(1+len(relax))
+			first := append([]Term{{a.end, 1}, {b.start, -1}, {y, big}}, relax...)
+			m.AddRow(name("SpectrumNonOverlap1", a.t, b.t), first, LE, rhs)
+			second := append([]Term{{b.end, 1}, {a.start, -1}, {y, -big}}, relax...)
+			m.AddRow(name("SpectrumNonOverlap2", a.t, b.t), second, LE, rhs-big)
+		}
+	}
+
+	f := &Formulation{MILP: m, In: in, Opt: opt, SlotLinks: usage}
+	f.decode = func(x []float64) plan.Plan {
+		var p plan.Plan
+		for _, r := range reqs {
+			if x[r.accept] < 0.5 {
+				continue
+			}
+			for _, rt := range r.routes {
+				if x[r.useMod[rt]] > 0.5 {
+					p = append(p, plan.Assignment{T: r.t, Route: rt,
+						Start: int(math.Round(x[r.start])) - 1, Slots: plan.Slots(in, r.t, rt)})
+				}
+			}
+		}
+		return p
+	}
+	return f
+}
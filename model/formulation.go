@@ -0,0 +1,104 @@
+package model
+
+import (
+	"fmt"
+
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+	"github.com/dilwar-crnlab/hpsr_2025/plan"
+	"github.com/dilwar-crnlab/hpsr_2025/spectrum"
+)
+
+// Options selects the problem variant a formulation models. The zero value
+// follows ilp.mod: all accepted blocks are pairwise disjoint and zones do
+// not restrict the blocks.
+type Options struct {
+	// PerLink keeps blocks apart only when the requests' routes share a
+	// link, instead of for every pair of accepted requests.
+	PerLink bool
+	// Zones requires every block to lie inside a single zone.
+	Zones bool
+	// GapPenalty is subtracted from the objective for every run of free
+	// slots on a link, and EdgeReward added for every block that starts at
+	// the low edge of its zone or ends at the high edge, so that the plan
+	// leaves the spectrum in few large pieces. They should stay below the
+	// smallest class weight divided by the number of runs or blocks, lest
+	// they outweigh acceptance. Only SlotIndexed, which has the per-slot
+	// occupancy, models them; see Check.
+	GapPenalty, EdgeReward float64
+}
+
+// Check returns an error when the formulation of Generators by that name
+// does not model every option set in opt, rather than let it ignore them.
+func (opt Options) Check(formulation string) error {
+	if formulation != "slot" && (opt.GapPenalty != 0 || opt.EdgeReward != 0) {
+		return fmt.Errorf("formulation %s does not model the gap penalty or the edge reward", formulation)
+	}
+	return nil
+}
+
+// Formulation is a MILP generated for an instance together with the
+// decoding of its solutions into plans.
+type Formulation struct {
+	*MILP
+	In  *instance.Instance
+	Opt Options
+	// SlotLinks is the total slot-links of the decoded plan (see
+	// plan.Plan.SlotLinks) as a linear expression.
+	SlotLinks []Term
+	decode    func(x []float64) plan.Plan
+}
+
+// Decode converts a solution vector into the plan it encodes.
+func (f *Formulation) Decode(x []float64) plan.Plan {
+	p := f.decode(x)
+	p.Sort(f.In)
+	return p
+}
+
+// Generator builds a formulation of an instance.
+type Generator func(in *instance.Instance, opt Options) *Formulation
+
+// Generators are the available formulations by name.
+var Generators = map[string]Generator{
+	"bigm": BigM,
+	"slot": SlotIndexed,
+}
+
+// name renders an indexed variable or row name as glpsol writes them in
+// CPLEX LP files, e.g. UseMod(0,2,p1,m1).
+func name(base string, idx ...any) string {
+	s := base + "("
+	for i, x := range idx {
+		if i > 0 {
+			s += ","
+		}
+		switch v := x.(type) {
+		case instance.Demand:
+			s += v.S + "," + v.D
+		default:
+			s += fmt.Sprint(v)
+		}
+	}
+	return s + ")"
+}
+
+// zoneRanges returns the zones blocks must lie in: the zone layout, or
+// the whole spectrum when zones are not enforced.
+func zoneRanges(in *instance.Instance, opt Options) []spectrum.Zone {
+	if opt.Zones {
+		return spectrum.Zones(in)
+	}
+	return []spectrum.Zone{{Name: "all", Lo: 0, Hi: in.NSlots}}
+}
+
+// conflicting reports whether routes a of t1 and b of t2 share a link.
+func conflicting(in *instance.Instance, t1 instance.Demand, p1 string, t2 instance.Demand, p2 string) bool {
+	for _, x := range plan.Links(in, t1, plan.Route{P: p1}) {
+		for _, y := range plan.Links(in, t2, plan.Route{P: p2}) {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
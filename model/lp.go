@@ -0,0 +1,85 @@
+package model
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// WriteLP writes m in CPLEX LP format, readable by glpsol --lp.
+func (m *MILP) WriteLP(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "\\* %s *\\\n\nMaximize\n obj:", m.Name)
+	m.writeTerms(bw, m.Obj)
+	fmt.Fprint(bw, "\n\nSubject To\n")
+	for i, r := range m.Rows {
+		n := r.Name
+		if n == "" {
+			n = "r" + strconv.Itoa(i+1)
+		}
+		fmt.Fprintf(bw, " %s:", n)
+		m.writeTerms(bw, r.Terms)
+		fmt.Fprintf(bw, " %s %s\n", r.Sense, num(r.RHS))
+	}
+	fmt.Fprint(bw, "\nBounds\n")
+	for _, v := range m.Vars {
+		if v.Kind == Binary {
+			continue
+		}
+		switch {
+		case math.IsInf(v.Hi, 1):
+			fmt.Fprintf(bw, " %s >= %s\n", v.Name, num(v.Lo))
+		case v.Lo == v.Hi:
+			fmt.Fprintf(bw, " %s = %s\n", v.Name, num(v.Lo))
+		default:
+			fmt.Fprintf(bw, " %s <= %s <= %s\n", num(v.Lo), v.Name, num(v.Hi))
+		}
+	}
+	for _, sec := range []struct {
+		title string
+		kind  Kind
+	}{{"General", Integer}, {"Binary", Binary}} {
+		first := true
+		for _, v := range m.Vars {
+			if v.Kind != sec.kind {
+				continue
+			}
+			if first {
+				fmt.Fprintf(bw, "\n%s\n", sec.title)
+				first = false
+			}
+			fmt.Fprintf(bw, " %s\n", v.Name)
+		}
+	}
+	fmt.Fprint(bw, "\nEnd\n")
+	return bw.Flush()
+}
+
+// writeTerms writes a linear expression, a few terms per line.
+func (m *MILP) writeTerms(w io.Writer, terms []Term) {
+	if len(terms) == 0 {
+		fmt.Fprint(w, " 0 "+m.Vars[0].Name)
+		return
+	}
+	for i, t := range terms {
+		if i > 0 && i%6 == 0 {
+			fmt.Fprint(w, "\n   ")
+		}
+		sign := "+"
+		c := t.Coef
+		if c < 0 {
+			sign, c = "-", -c
+		}
+		if c == 1 {
+			fmt.Fprintf(w, " %s %s", sign, m.Vars[t.Var].Name)
+		} else {
+			fmt.Fprintf(w, " %s %s %s", sign, num(c), m.Vars[t.Var].Name)
+		}
+	}
+}
+
+func num(x float64) string {
+	return strconv.FormatFloat(x, 'g', -1, 64)
+}
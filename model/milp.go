@@ -0,0 +1,158 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Sense is the relation of a constraint row to its right-hand side.
+type Sense int
+
+const (
+	LE Sense = iota
+	GE
+	EQ
+)
+
+func (s Sense) String() string {
+	switch s {
+	case GE:
+		return ">="
+	case EQ:
+		return "="
+	}
+	return "<="
+}
+
+// Var is a decision variable with bounds Lo <= x <= Hi (Hi may be +Inf).
+type Var struct {
+	Name   string
+	Kind   Kind
+	Lo, Hi float64
+}
+
+// Term is a coefficient of a variable in a row or the objective.
+type Term struct {
+	Var  int
+	Coef float64
+}
+
+// Row is a linear constraint sum(Terms) Sense RHS.
+type Row struct {
+	Name  string
+	Terms []Term
+	Sense Sense
+	RHS   float64
+}
+
+// MILP is a mixed-integer linear program that maximises Obj.
+type MILP struct {
+	Name  string
+	Vars  []Var
+	Rows  []Row
+	Obj   []Term
+	index map[string]int
+}
+
+// NewMILP returns an empty program.
+func NewMILP(name string) *MILP {
+	return &MILP{Name: name, index: map[string]int{}}
+}
+
+// AddVar adds a variable and returns its index. Binary variables get the
+// bounds [0,1] regardless of lo and hi.
+func (m *MILP) AddVar(name string, kind Kind, lo, hi float64) int {
+	if _, dup := m.index[name]; dup {
+		panic(fmt.Sprintf("model: duplicate variable %s", name))
+	}
+	if kind == Binary {
+		lo, hi = 0, 1
+	}
+	m.Vars = append(m.Vars, Var{name, kind, lo, hi})
+	m.index[name] = len(m.Vars) - 1
+	return len(m.Vars) - 1
+}
+
+// Lookup returns the index of the variable called name.
+func (m *MILP) Lookup(name string) (int, bool) {
+	i, ok := m.index[name]
+	return i, ok
+}
+
+// AddRow adds a constraint, merging repeated variables and dropping zero
+// coefficients.
+func (m *MILP) AddRow(name string, terms []Term, sense Sense, rhs float64) {
+	m.Rows = append(m.Rows, Row{name, merge(terms), sense, rhs})
+}
+
+// Maximize adds terms to the objective.
+func (m *MILP) Maximize(terms ...Term) {
+	m.Obj = merge(append(m.Obj, terms...))
+}
+
+func merge(terms []Term) []Term {
+	sum := map[int]float64{}
+	for _, t := range terms {
+		sum[t.Var] += t.Coef
+	}
+	out := make([]Term, 0, len(sum))
+	for v, c := range sum {
+		if c != 0 {
+			out = append(out, Term{v, c})
+		}
+	}
+	sort.Slice(out, func(a, b int) bool { return out[a].Var < out[b].Var })
+	return out
+}
+
+// Size counts the variables and constraints of m by kind.
+func (m *MILP) Size() Size {
+	var s Size
+	fam := map[Kind]int{}
+	for _, v := range m.Vars {
+		fam[v.Kind]++
+	}
+	for _, k := range []Kind{Binary, Integer, Continuous} {
+		s.Vars = append(s.Vars, Family{Name: k.String(), Kind: k, Count: fam[k]})
+	}
+	nz := 0
+	for _, r := range m.Rows {
+		nz += len(r.Terms)
+	}
+	s.Cons = []Family{{Name: "rows", Count: len(m.Rows)}}
+	s.Nonzeros = nz
+	return s
+}
+
+// Value evaluates the objective at x.
+func (m *MILP) Value(x []float64) float64 {
+	var v float64
+	for _, t := range m.Obj {
+		v += t.Coef * x[t.Var]
+	}
+	return v
+}
+
+// Feasible checks x against the bounds, integrality and rows of m within
+// tolerance tol and returns the first violation.
+func (m *MILP) Feasible(x []float64, tol float64) error {
+	for i, v := range m.Vars {
+		if x[i] < v.Lo-tol || x[i] > v.Hi+tol {
+			return fmt.Errorf("%s = %g outside [%g,%g]", v.Name, x[i], v.Lo, v.Hi)
+		}
+		if v.Kind != Continuous && math.Abs(x[i]-math.Round(x[i])) > tol {
+			return fmt.Errorf("%s = %g is not integral", v.Name, x[i])
+		}
+	}
+	for _, r := range m.Rows {
+		var lhs float64
+		for _, t := range r.Terms {
+			lhs += t.Coef * x[t.Var]
+		}
+		if r.Sense != GE && lhs > r.RHS+tol || r.Sense != LE && lhs < r.RHS-tol {
+			return fmt.Errorf("%s: %g %v %g violated", r.Name, lhs, r.Sense, r.RHS)
+		}
+	}
+	return nil
+}
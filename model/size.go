@@ -0,0 +1,134 @@
+// Package model describes the ILP formulation of ilp.mod over a concrete
+// instance.
+package model
+
+import "github.com/dilwar-crnlab/hpsr_2025/instance"
+
+// Kind is the domain of a variable family.
+type Kind int
+
+const (
+	Binary Kind = iota
+	Integer
+	Continuous
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Binary:
+		return "binary"
+	case Integer:
+		return "integer"
+	}
+	return "continuous"
+}
+
+// Family is a variable or constraint family of the model and the number of
+// its members on an instance.
+type Family struct {
+	Name  string
+	Kind  Kind // variables only
+	Count int
+}
+
+// Size is the size of the model generated for an instance.
+type Size struct {
+	Vars     []Family
+	Cons     []Family
+	Nonzeros int // constraint matrix entries, when known
+}
+
+// Count returns the total number of variables of kind k.
+func (s Size) Count(k Kind) int {
+	n := 0
+	for _, f := range s.Vars {
+		if f.Kind == k {
+			n += f.Count
+		}
+	}
+	return n
+}
+
+// Variables is the total number of variables.
+func (s Size) Variables() int {
+	n := 0
+	for _, f := range s.Vars {
+		n += f.Count
+	}
+	return n
+}
+
+// Constraints is the total number of constraints.
+func (s Size) Constraints() int {
+	n := 0
+	for _, f := range s.Cons {
+		n += f.Count
+	}
+	return n
+}
+
+// Pairs is the number of request pairs t1 < t2, the index set of the
+// disjunction variables y and the SpectrumNonOverlap constraints.
+func Pairs(in *instance.Instance) int {
+	t := len(in.Traffic)
+	return t * (t - 1) / 2
+}
+
+// Combos counts the candidate (t,p,m) combinations of FEAS_MOD, in total and
+// restricted to those whose path distance is within the modulation's reach.
+func Combos(in *instance.Instance) (all, reachable int) {
+	for _, t := range in.Traffic {
+		for _, p := range in.Paths[t] {
+			d := in.PathDist(t, p)
+			for _, m := range in.FeasMod[instance.PathKey{T: t, P: p}] {
+				all++
+				if d <= in.R[m] {
+					reachable++
+				}
+			}
+		}
+	}
+	return all, reachable
+}
+
+// BigMSize counts the variables and constraints ilp.mod generates for in.
+func BigMSize(in *instance.Instance) Size {
+	var paths, tpm, tpml int
+	for _, t := range in.Traffic {
+		paths += len(in.Paths[t])
+		for _, p := range in.Paths[t] {
+			k := instance.PathKey{T: t, P: p}
+			tpm += len(in.FeasMod[k])
+			tpml += len(in.FeasMod[k]) * len(in.PathLinks[k])
+		}
+	}
+	nt := len(in.Traffic)
+	pairs := Pairs(in)
+	return Size{
+		Vars: []Family{
+			{"Accept", Binary, nt},
+			{"UsePath", Binary, paths},
+			{"UseMod", Binary, tpm},
+			{"Route", Binary, tpml},
+			{"PathDist", Continuous, paths},
+			{"LeftAlloc", Binary, nt},
+			{"RightAlloc", Binary, nt},
+			{"StartSlot", Integer, nt},
+			{"EndSlot", Integer, nt},
+			{"S_max", Integer, 1},
+			{"y", Binary, pairs},
+		},
+		Cons: []Family{
+			{Name: "PathSelection", Count: nt},
+			{Name: "ModulationSelection", Count: paths},
+			{Name: "ComputePathDist", Count: paths},
+			{Name: "ModulationFeas", Count: tpm},
+			{Name: "LeftRightAlloc", Count: nt},
+			{Name: "SlotBlockLength", Count: tpm},
+			{Name: "SpectrumNonOverlap1", Count: pairs},
+			{Name: "SpectrumNonOverlap2", Count: pairs},
+			{Name: "MaxSpectrum", Count: nt},
+			{Name: "SlotLimit", Count: 1},
+		},
+	}
+}
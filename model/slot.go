@@ -0,0 +1,131 @@
+package model
+
+import (
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+	"github.com/dilwar-crnlab/hpsr_2025/plan"
+)
+
+// SlotIndexed generates the start-slot formulation: a binary x[t,p,m,s]
+// for "request t starts at slot s on path p with modulation m", created
+// only for reach-feasible (p,m) and for starts whose N_req block lies in a
+// zone. A block at s occupies [s, s+N_req+G) clipped at N_slots, the guard
+// band following the block, and per-slot capacity rows allow at most one
+// occupied block per slot:
+//
+//	sum_{p,m,s} x[t,p,m,s] = Accept[t]                  (Assign)
+//	sum over x occupying slot q on link l <= 1         (Capacity, per l and q)
+//	S_max >= sum_{p,m,s} (s+N_req) x[t,p,m,s]           (MaxSpectrum)
+//
+// Contiguity, zones and the guard band are thereby encoded in the choice
+// of the variables. Without opt.PerLink a single capacity row per slot
+// spans all requests, mirroring the pairwise disjointness of ilp.mod.
+// Slots are 0-based.
+//
+// With opt.GapPenalty a continuous Gap[l,q] >= occ[l,q-1] - occ[l,q]
+// (occ[l,-1] = 1) marks slot q as the start of a free run on link l,
+// where occ[l,q] is the capacity row's left-hand side; the penalised
+// objective drives it to the indicator.
+func SlotIndexed(in *instance.Instance, opt Options) *Formulation {
+	m := NewMILP("slot")
+	type xvar struct {
+		t     instance.Demand
+		r     plan.Route
+		start int
+	}
+	var xs []xvar
+	var vidx []int
+	var usage []Term
+	smax := m.AddVar("S_max", Integer, 0, float64(in.NSlots))
+	// occupy[link][slot] lists the x variables occupying the slot.
+	occupy := map[instance.Link][][]Term{}
+	all := instance.Link{I: "*", J: "*"}
+	addOccupancy := func(l instance.Link, lo, hi, v int) {
+		if occupy[l] == nil {
+			occupy[l] = make([][]Term, in.NSlots)
+		}
+		for q := lo; q < hi; q++ {
+			occupy[l][q] = append(occupy[l][q], Term{v, 1})
+		}
+	}
+	zones := zoneRanges(in, opt)
+	for _, t := range in.Traffic {
+		rs := plan.Candidates(in, t)
+		if len(rs) == 0 {
+			continue
+		}
+		a := m.AddVar(name("Accept", t), Binary, 0, 1)
+		m.Maximize(Term{a, in.Weight(t)})
+		assign := []Term{{a, -1}}
+		span := []Term{{smax, 1}}
+		for _, r := range rs {
+			n := plan.Slots(in, t, r)
+			links := plan.Links(in, t, r)
+			for _, z := range zones {
+				for s := z.Lo; s+n <= z.Hi; s++ {
+					v := m.AddVar(name("x", t, r.P, r.M, s), Binary, 0, 1)
+					xs = append(xs, xvar{t, r, s})
+					vidx = append(vidx, v)
+					assign = append(assign, Term{v, 1})
+					span = append(span, Term{v, -float64(s + n)})
+					usage = append(usage, Term{v, float64(n * len(links))})
+					hi := min(s+n+in.G, in.NSlots)
+					if opt.EdgeReward != 0 && (s == z.Lo || s+n == z.Hi) {
+						m.Maximize(Term{v, opt.EdgeReward})
+					}
+					if opt.PerLink {
+						for _, l := range links {
+							addOccupancy(l, s, hi, v)
+						}
+					} else {
+						addOccupancy(all, s, hi, v)
+					}
+				}
+			}
+		}
+		m.AddRow(name("Assign", t), assign, EQ, 0)
+		m.AddRow(name("MaxSpectrum", t), span, GE, 0)
+	}
+	links := in.Links
+	if !opt.PerLink {
+		links = []instance.Link{all}
+	}
+	for _, l := range links {
+		for q, terms := range occupy[l] {
+			if len(terms) > 1 {
+				m.AddRow(name("Capacity", l.I, l.J, q), terms, LE, 1)
+			}
+		}
+		if opt.GapPenalty == 0 {
+			continue
+		}
+		occ := occupy[l]
+		if occ == nil {
+			occ = make([][]Term, in.NSlots)
+		}
+		for q := range occ {
+			g := m.AddVar(name("Gap", l.I, l.J, q), Continuous, 0, 1)
+			m.Maximize(Term{g, -opt.GapPenalty})
+			row := append([]Term{{g, 1}}, occ[q]...)
+			rhs := 1.0
+			if q > 0 {
+				for _, t := range occ[q-1] {
+					row = append(row, Term{t.Var, -t.Coef})
+				}
+				rhs = 0
+			}
+			m.AddRow(name("GapStart", l.I, l.J, q), row, GE, rhs)
+		}
+	}
+
+	f := &Formulation{MILP: m, In: in, Opt: opt, SlotLinks: usage}
+	f.decode = func(x []float64) plan.Plan {
+		var p plan.Plan
+		for i, xv := range xs {
+			if x[vidx[i]] > 0.5 {
+				p = append(p, plan.Assignment{T: xv.t, Route: xv.r, Start: xv.start, Slots: plan.Slots(in, xv.t, xv.r)})
+			}
+		}
+		return p
+	}
+	return f
+}
@@ -0,0 +1,92 @@
+package model
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+	"github.com/dilwar-crnlab/hpsr_2025/plan"
+	"github.com/dilwar-crnlab/hpsr_2025/spectrum"
+)
+
+// TestSlotGaps checks that the gap variables at their least feasible
+// values count the runs of free slots of a plan leaving a gap in front of
+// the first block on every link.
+func TestSlotGaps(t *testing.T) {
+	in, err := instance.Load("../testdata/line4.dat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	const penalty = 0.01
+	f := SlotIndexed(in, Options{PerLink: true, Zones: true, GapPenalty: penalty})
+	rt := plan.Shortest(in)
+	starts := map[instance.Demand]int{{S: "0", D: "1"}: 2, {S: "2", D: "3"}: 1, {S: "1", D: "3"}: 7, {S: "0", D: "2"}: 10}
+	var p plan.Plan
+	for d, s := range starts {
+		p = append(p, plan.Assignment{T: d, Route: rt[d], Start: s, Slots: plan.Slots(in, d, rt[d])})
+	}
+	if err := p.Validate(in); err != nil {
+		t.Fatal(err)
+	}
+
+	m := f.MILP
+	x := make([]float64, len(m.Vars))
+	set := func(name string, v float64) {
+		i, ok := m.Lookup(name)
+		if !ok {
+			t.Fatalf("no variable %s", name)
+		}
+		x[i] = v
+	}
+	var weight float64
+	for _, a := range p {
+		set(name("Accept", a.T), 1)
+		set(name("x", a.T, a.P, a.M, a.Start), 1)
+		weight += in.Weight(a.T)
+	}
+	set("S_max", float64(p.SMax()))
+	var gaps float64
+	for _, r := range m.Rows {
+		idx, ok := strings.CutPrefix(r.Name, "GapStart")
+		if !ok {
+			continue
+		}
+		g, _ := m.Lookup("Gap" + idx)
+		lhs := 0.0
+		for _, t := range r.Terms {
+			if t.Var != g {
+				lhs += t.Coef * x[t.Var]
+			}
+		}
+		x[g] = max(0, r.RHS-lhs)
+		gaps += x[g]
+	}
+	if err := m.Feasible(x, 1e-9); err != nil {
+		t.Fatal(err)
+	}
+
+	want := freeRuns(p.Grid(in))
+	if want != 8 {
+		t.Fatalf("plan leaves %d free runs, want 8", want)
+	}
+	if gaps != float64(want) {
+		t.Errorf("gap variables sum to %g, plan leaves %d free runs", gaps, want)
+	}
+	if obj, w := m.Value(x), weight-penalty*float64(want); math.Abs(obj-w) > 1e-9 {
+		t.Errorf("objective %g, want %g", obj, w)
+	}
+}
+
+// freeRuns counts the runs of free slots summed over the links of g.
+func freeRuns(g *spectrum.Grid) int {
+	n := 0
+	for li := range g.Links {
+		for s := 0; s < g.Slots; s++ {
+			if g.Owner(li, s) == spectrum.Free && (s == 0 || g.Owner(li, s-1) != spectrum.Free) {
+				n++
+			}
+		}
+	}
+	return n
+}
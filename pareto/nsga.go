@@ -0,0 +1,271 @@
+package pareto
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+	"github.com/dilwar-crnlab/hpsr_2025/plan"
+	"github.com/dilwar-crnlab/hpsr_2025/spectrum"
+)
+
+// NSGAOptions controls NSGA.
+type NSGAOptions struct {
+	Objective   string // SMax or SlotLinks
+	Population  int
+	Generations int
+	Seed        int64
+	Deadline    time.Time // zero for none
+}
+
+// individual encodes a plan by a route choice per request, 0 rejecting it
+// and i > 0 taking candidate i-1, and a priority key per request that
+// orders the placement.
+type individual struct {
+	route    []int
+	key      []float64
+	weight   float64
+	cost     int
+	plan     plan.Plan
+	rank     int
+	crowding float64
+}
+
+type nsga struct {
+	in    *instance.Instance
+	opt   NSGAOptions
+	rng   *rand.Rand
+	ts    []instance.Demand
+	rs    [][]plan.Route
+	zones []spectrum.Zone
+}
+
+// NSGA approximates the front with NSGA-II: non-dominated sorting and
+// crowding distance select among parents and offspring, which are bred by
+// binary tournaments, uniform crossover and per-gene mutation. A genome is
+// decoded by placing the chosen routes in key order at the lowest start
+// inside a zone that keeps the guard band on shared links; requests that
+// do not fit are rejected. The front of the last population is returned.
+func NSGA(in *instance.Instance, opt NSGAOptions) []Point {
+	g := &nsga{in: in, opt: opt, rng: rand.New(rand.NewSource(opt.Seed)), zones: spectrum.Zones(in)}
+	for _, t := range in.Traffic {
+		if rs := plan.Candidates(in, t); len(rs) > 0 {
+			g.ts = append(g.ts, t)
+			g.rs = append(g.rs, rs)
+		}
+	}
+	n := max(opt.Population, 4)
+	pop := make([]*individual, n)
+	for i := range pop {
+		pop[i] = g.random()
+	}
+	g.sort(pop)
+	for gen := 0; gen < opt.Generations; gen++ {
+		if !opt.Deadline.IsZero() && time.Now().After(opt.Deadline) {
+			break
+		}
+		next := pop
+		for len(next) < 2*n {
+			a, b := g.cross(g.tournament(pop), g.tournament(pop))
+			next = append(next, g.mutate(a), g.mutate(b))
+		}
+		pop = g.survive(next, n)
+	}
+	var pts []Point
+	for _, x := range pop {
+		if x.rank == 0 {
+			pts = append(pts, Point{Weight: x.weight, Cost: x.cost, Plan: x.plan})
+		}
+	}
+	return Front(pts)
+}
+
+func (g *nsga) random() *individual {
+	x := &individual{route: make([]int, len(g.ts)), key: make([]float64, len(g.ts))}
+	// Mostly accepting genomes; the cheaper ends of the front come from
+	// rejections found by mutation.
+	for i := range g.ts {
+		if g.rng.Float64() < 0.9 {
+			x.route[i] = 1 + g.rng.Intn(len(g.rs[i]))
+		}
+		x.key[i] = g.rng.Float64()
+	}
+	g.decode(x)
+	return x
+}
+
+// decode lays out x and evaluates its objectives.
+func (g *nsga) decode(x *individual) {
+	order := make([]int, len(g.ts))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return x.key[order[a]] < x.key[order[b]] })
+	var p plan.Plan
+	for _, i := range order {
+		if x.route[i] == 0 {
+			continue
+		}
+		if a, ok := g.fit(p, g.ts[i], g.rs[i][x.route[i]-1]); ok {
+			p = append(p, a)
+		}
+	}
+	p.Sort(g.in)
+	x.plan, x.weight, x.cost = p, p.Weight(g.in), Cost(g.in, p, g.opt.Objective)
+}
+
+// fit returns t on route r at the lowest start inside a zone compatible
+// with p.
+func (g *nsga) fit(p plan.Plan, t instance.Demand, r plan.Route) (plan.Assignment, bool) {
+	a := plan.Assignment{T: t, Route: r, Slots: plan.Slots(g.in, t, r)}
+	var shared []plan.Assignment
+	for _, b := range p {
+		if plan.Share(g.in, a, b) {
+			shared = append(shared, b)
+		}
+	}
+	for _, z := range g.zones {
+		a.Start = z.Lo
+		for moved := true; moved; {
+			moved = false
+			for _, b := range shared {
+				if !plan.Apart(a, b, g.in.G) {
+					a.Start = b.End() + g.in.G
+					moved = true
+				}
+			}
+		}
+		if a.End() <= z.Hi {
+			return a, true
+		}
+	}
+	return plan.Assignment{}, false
+}
+
+// dominates reports whether a is at least as good as b in both
+// objectives and better in one.
+func dominates(a, b *individual) bool {
+	return a.weight >= b.weight && a.cost <= b.cost && (a.weight > b.weight || a.cost < b.cost)
+}
+
+// sort assigns the non-domination ranks and crowding distances of pop and
+// returns its fronts.
+func (g *nsga) sort(pop []*individual) [][]*individual {
+	beats := make([][]int, len(pop))
+	count := make([]int, len(pop))
+	var fronts [][]*individual
+	var cur []int
+	for i, a := range pop {
+		for j, b := range pop {
+			if dominates(a, b) {
+				beats[i] = append(beats[i], j)
+			} else if dominates(b, a) {
+				count[i]++
+			}
+		}
+		if count[i] == 0 {
+			cur = append(cur, i)
+		}
+	}
+	for rank := 0; len(cur) > 0; rank++ {
+		var front []*individual
+		var next []int
+		for _, i := range cur {
+			pop[i].rank = rank
+			front = append(front, pop[i])
+			for _, j := range beats[i] {
+				if count[j]--; count[j] == 0 {
+					next = append(next, j)
+				}
+			}
+		}
+		crowd(front)
+		fronts = append(fronts, front)
+		cur = next
+	}
+	return fronts
+}
+
+// crowd sets the crowding distances within a front.
+func crowd(front []*individual) {
+	for _, x := range front {
+		x.crowding = 0
+	}
+	for _, obj := range []func(*individual) float64{
+		func(x *individual) float64 { return x.weight },
+		func(x *individual) float64 { return float64(x.cost) },
+	} {
+		sort.SliceStable(front, func(a, b int) bool { return obj(front[a]) < obj(front[b]) })
+		lo, hi := obj(front[0]), obj(front[len(front)-1])
+		front[0].crowding, front[len(front)-1].crowding = math.Inf(1), math.Inf(1)
+		if hi == lo {
+			continue
+		}
+		for i := 1; i < len(front)-1; i++ {
+			front[i].crowding += (obj(front[i+1]) - obj(front[i-1])) / (hi - lo)
+		}
+	}
+}
+
+// better is the crowded-comparison order.
+func better(a, b *individual) bool {
+	return a.rank < b.rank || a.rank == b.rank && a.crowding > b.crowding
+}
+
+func (g *nsga) tournament(pop []*individual) *individual {
+	a, b := pop[g.rng.Intn(len(pop))], pop[g.rng.Intn(len(pop))]
+	if better(b, a) {
+		return b
+	}
+	return a
+}
+
+// cross mixes the genes of a and b uniformly, with probability 0.9.
+func (g *nsga) cross(a, b *individual) (*individual, *individual) {
+	c := &individual{route: append([]int(nil), a.route...), key: append([]float64(nil), a.key...)}
+	d := &individual{route: append([]int(nil), b.route...), key: append([]float64(nil), b.key...)}
+	if g.rng.Float64() < 0.9 {
+		for i := range c.route {
+			if g.rng.Intn(2) == 0 {
+				c.route[i], d.route[i] = d.route[i], c.route[i]
+				c.key[i], d.key[i] = d.key[i], c.key[i]
+			}
+		}
+	}
+	return c, d
+}
+
+// mutate redraws each route choice and key with probability 1/n and
+// decodes x.
+func (g *nsga) mutate(x *individual) *individual {
+	p := 1 / float64(max(len(x.route), 1))
+	for i := range x.route {
+		if g.rng.Float64() < p {
+			x.route[i] = g.rng.Intn(len(g.rs[i]) + 1)
+		}
+		if g.rng.Float64() < p {
+			x.key[i] = g.rng.Float64()
+		}
+	}
+	g.decode(x)
+	return x
+}
+
+// survive keeps the best n of pop by rank and, in the last front taken,
+// by crowding distance.
+func (g *nsga) survive(pop []*individual, n int) []*individual {
+	var next []*individual
+	for _, front := range g.sort(pop) {
+		if len(next)+len(front) > n {
+			sort.SliceStable(front, func(a, b int) bool { return front[a].crowding > front[b].crowding })
+			front = front[:n-len(next)]
+		}
+		next = append(next, front...)
+		if len(next) == n {
+			break
+		}
+	}
+	return next
+}
@@ -0,0 +1,125 @@
+// Package pareto traces the trade-off between the accepted weight and the
+// spectrum used, which the lexicographic objective of ilp.mod hides: a
+// plan is on the Pareto front when no other plan accepts at least as much
+// weight with at most as much spectrum, and is better in one of the two.
+//
+// Epsilon computes the front exactly with the epsilon-constraint method
+// on a MILP formulation; NSGA evolves an approximation for instances too
+// large for that.
+package pareto
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+	"github.com/dilwar-crnlab/hpsr_2025/milp"
+	"github.com/dilwar-crnlab/hpsr_2025/model"
+	"github.com/dilwar-crnlab/hpsr_2025/plan"
+)
+
+// Spectrum costs that can be traded against the accepted weight.
+const (
+	SMax      = "smax"      // highest used slot, S_max of ilp.mod
+	SlotLinks = "slotlinks" // occupied slots summed over links
+)
+
+// Cost returns the spectrum cost of p by objective.
+func Cost(in *instance.Instance, p plan.Plan, objective string) int {
+	if objective == SlotLinks {
+		return p.SlotLinks(in)
+	}
+	return p.SMax()
+}
+
+// Point is a plan on the front.
+type Point struct {
+	Weight  float64
+	Cost    int
+	Plan    plan.Plan
+	Optimal bool // proven Pareto-optimal
+}
+
+// Options controls Epsilon.
+type Options struct {
+	Objective   string // SMax or SlotLinks
+	Formulation string // a model.Generators name
+	NodeLimit   int    // branch-and-bound nodes per solve, <= 0 for no limit
+	Deadline    time.Time
+}
+
+// Epsilon traces the front from the highest weight down. For a cost bound
+// eps, starting unbounded, it maximises the weight subject to cost <= eps,
+// then minimises the cost at that weight, and continues with eps one below
+// the cost found, until nothing but the empty plan remains. Blocks are
+// kept apart per link and confined to zones. Points are ordered by
+// decreasing weight; solves stopped by a limit leave points not proven
+// optimal. When a limit stops a weight maximisation before it finds any
+// plan, the points below are unknown and truncated is set.
+func Epsilon(in *instance.Instance, opt Options) (pts []Point, truncated bool, err error) {
+	gen, ok := model.Generators[opt.Formulation]
+	if !ok {
+		return nil, false, fmt.Errorf("unknown formulation %q", opt.Formulation)
+	}
+	if opt.Objective != SMax && opt.Objective != SlotLinks {
+		return nil, false, fmt.Errorf("unknown objective %q (have %s, %s)", opt.Objective, SMax, SlotLinks)
+	}
+	mo := milp.Options{NodeLimit: opt.NodeLimit, Deadline: opt.Deadline}
+	eps := -1 // no bound
+	for {
+		f := gen(in, model.Options{PerLink: true, Zones: true})
+		cost := f.SlotLinks
+		if opt.Objective == SMax {
+			v, _ := f.Lookup("S_max")
+			cost = []model.Term{{Var: v, Coef: 1}}
+		}
+		if eps >= 0 {
+			f.AddRow("Epsilon", cost, model.LE, float64(eps))
+		}
+		r := milp.Solve(f.MILP, mo)
+		if r.X == nil {
+			truncated = r.Status != milp.Infeasible
+			break
+		}
+		weight := f.Obj
+		f.AddRow("KeepWeight", weight, model.GE, r.Obj-1e-6)
+		f.Obj = nil
+		for _, t := range cost {
+			f.Maximize(model.Term{Var: t.Var, Coef: -t.Coef})
+		}
+		mo2 := mo
+		mo2.Incumbent = r.X
+		r2 := milp.Solve(f.MILP, mo2)
+		if r2.X == nil {
+			r2.X, r2.Status = r.X, milp.Feasible
+		}
+		p := f.Decode(r2.X)
+		pt := Point{Weight: p.Weight(in), Cost: Cost(in, p, opt.Objective), Plan: p,
+			Optimal: r.Status == milp.Optimal && r2.Status == milp.Optimal}
+		pts = append(pts, pt)
+		if pt.Cost == 0 || pt.Weight == 0 {
+			break
+		}
+		eps = pt.Cost - 1
+	}
+	return pts, truncated, nil
+}
+
+// Front keeps the non-dominated points of pts, one per (weight, cost),
+// ordered by decreasing weight.
+func Front(pts []Point) []Point {
+	sort.SliceStable(pts, func(a, b int) bool {
+		if pts[a].Weight != pts[b].Weight {
+			return pts[a].Weight > pts[b].Weight
+		}
+		return pts[a].Cost < pts[b].Cost
+	})
+	var front []Point
+	for _, p := range pts {
+		if len(front) == 0 || p.Cost < front[len(front)-1].Cost {
+			front = append(front, p)
+		}
+	}
+	return front
+}
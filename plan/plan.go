@@ -0,0 +1,167 @@
+// Package plan holds static routing and spectrum assignments of an
+// instance, the solutions of ilp.mod and of the heuristics that stand in for
+// it.
+package plan
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+	"github.com/dilwar-crnlab/hpsr_2025/spectrum"
+)
+
+// Route is a (path, modulation) choice of a request.
+type Route struct {
+	P, M string
+}
+
+// Assignment is an accepted request with its route and spectrum block.
+// Start is the 0-based first slot; the block spans Slots = N_req slots and
+// is followed by a guard band of G slots towards the next block on a
+// shared link, as in SpectrumNonOverlap.
+type Assignment struct {
+	T instance.Demand
+	Route
+	Start, Slots int
+}
+
+// End is one past the last slot of a's block.
+func (a Assignment) End() int { return a.Start + a.Slots }
+
+// Plan is a set of accepted requests.
+type Plan []Assignment
+
+// SMax is the number of slots used up to the highest occupied one, S_max of
+// ilp.mod.
+func (p Plan) SMax() int {
+	m := 0
+	for _, a := range p {
+		if a.End() > m {
+			m = a.End()
+		}
+	}
+	return m
+}
+
+// SlotLinks is the total number of slots occupied summed over links.
+func (p Plan) SlotLinks(in *instance.Instance) int {
+	n := 0
+	for _, a := range p {
+		n += a.Slots * len(in.PathLinks[instance.PathKey{T: a.T, P: a.P}])
+	}
+	return n
+}
+
+// Weight is the class-weighted number of accepted requests, the objective
+// TotalAccepted.
+func (p Plan) Weight(in *instance.Instance) float64 {
+	var w float64
+	for _, a := range p {
+		w += in.Weight(a.T)
+	}
+	return w
+}
+
+// Grid lays p out on a spectrum grid, owner ids being positions in p plus
+// one. As in the simulator, a block holds its guard band too, up to N_slots.
+func (p Plan) Grid(in *instance.Instance) *spectrum.Grid {
+	g := spectrum.NewGrid(in)
+	for i, a := range p {
+		g.Assign(g.Indices(Links(in, a.T, a.Route)), a.Start, min(a.End()+in.G, in.NSlots), i+1)
+	}
+	return g
+}
+
+// Fragmentation is the external fragmentation of the spectrum left by p.
+func (p Plan) Fragmentation(in *instance.Instance) float64 {
+	return p.Grid(in).Fragmentation()
+}
+
+// Sort orders p by request as listed in TRAFFIC.
+func (p Plan) Sort(in *instance.Instance) {
+	pos := map[instance.Demand]int{}
+	for i, t := range in.Traffic {
+		pos[t] = i
+	}
+	sort.Slice(p, func(a, b int) bool { return pos[p[a].T] < pos[p[b].T] })
+}
+
+// Links returns the canonical links of route r of t.
+func Links(in *instance.Instance, t instance.Demand, r Route) []instance.Link {
+	ls := in.PathLinks[instance.PathKey{T: t, P: r.P}]
+	c := make([]instance.Link, len(ls))
+	for i, l := range ls {
+		c[i] = in.Canonical(l)
+	}
+	return c
+}
+
+// Share reports whether routes a and b have a link in common.
+func Share(in *instance.Instance, a, b Assignment) bool {
+	return shareLinks(Links(in, a.T, a.Route), Links(in, b.T, b.Route))
+}
+
+func shareLinks(a, b []instance.Link) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Apart reports whether the blocks of a and b are separated by at least g
+// guard slots.
+func Apart(a, b Assignment, g int) bool {
+	return a.End()+g <= b.Start || b.End()+g <= a.Start
+}
+
+// Validate checks that every assignment uses a candidate path and a
+// reach-feasible modulation with its N_req slots inside one zone, that no
+// request is accepted twice, and that blocks on shared links keep the guard
+// band.
+func (p Plan) Validate(in *instance.Instance) error {
+	seen := map[instance.Demand]bool{}
+	zones := spectrum.Zones(in)
+	for i, a := range p {
+		if seen[a.T] {
+			return fmt.Errorf("request %v accepted twice", a.T)
+		}
+		seen[a.T] = true
+		n, ok := in.NReq[instance.ModKey{T: a.T, P: a.P, M: a.M}]
+		if !ok {
+			return fmt.Errorf("request %v: (%s,%s) is not a candidate", a.T, a.P, a.M)
+		}
+		if in.PathDist(a.T, a.P) > in.R[a.M] {
+			return fmt.Errorf("request %v: path %s exceeds the reach of %s", a.T, a.P, a.M)
+		}
+		if a.Slots != n {
+			return fmt.Errorf("request %v: %d slots, N_req is %d", a.T, a.Slots, n)
+		}
+		if a.Start < 0 || a.End() > in.NSlots {
+			return fmt.Errorf("request %v: block [%d,%d) outside N_slots", a.T, a.Start, a.End())
+		}
+		if !inZone(zones, a) {
+			return fmt.Errorf("request %v: block [%d,%d) not inside one zone", a.T, a.Start, a.End())
+		}
+		for _, b := range p[:i] {
+			if Share(in, a, b) && !Apart(a, b, in.G) {
+				return fmt.Errorf("requests %v and %v overlap on a shared link", a.T, b.T)
+			}
+		}
+	}
+	return nil
+}
+
+// inZone reports whether the block of a lies inside one of zones.
+func inZone(zones []spectrum.Zone, a Assignment) bool {
+	for _, z := range zones {
+		if a.Start >= z.Lo && a.End() <= z.Hi {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,40 @@
+package plan
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+)
+
+func TestFirstFitDecreasingZones(t *testing.T) {
+	b, err := os.ReadFile("../testdata/line3.dat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Zones [0,4) and [4,12): (1,2) takes [0,3), which leaves (0,2) the
+	// block [3,5) across the zone border unless it moves up to [4,6).
+	data := strings.Replace(string(b), "param C_z := 1 6, 2 6;", "param C_z := 1 4, 2 8;", 1)
+	in, err := instance.Parse(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	spanning := FirstFitDecreasing(in, in.NSlots)
+	if err := spanning.Validate(in); err == nil || !strings.Contains(err.Error(), "not inside one zone") {
+		t.Errorf("zone-blind plan %v: got error %v, want a block across zones", spanning, err)
+	}
+	p := FirstFitDecreasingZones(in)
+	if err := p.Validate(in); err != nil {
+		t.Fatal(err)
+	}
+	want := map[instance.Demand]int{{S: "1", D: "2"}: 0, {S: "0", D: "2"}: 4, {S: "0", D: "1"}: 0}
+	if len(p) != len(want) {
+		t.Fatalf("%d requests accepted, want %d", len(p), len(want))
+	}
+	for _, a := range p {
+		if a.Start != want[a.T] {
+			t.Errorf("request %v at slot %d, want %d", a.T, a.Start, want[a.T])
+		}
+	}
+}
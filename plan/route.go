@@ -0,0 +1,169 @@
+package plan
+
+import (
+	"sort"
+
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+	"github.com/dilwar-crnlab/hpsr_2025/spectrum"
+	"github.com/dilwar-crnlab/hpsr_2025/units"
+)
+
+// Candidates lists the reach-feasible routes of t, from the fewest
+// required slots upwards and in PATHS order between equals.
+func Candidates(in *instance.Instance, t instance.Demand) []Route {
+	var rs []Route
+	for _, p := range in.Paths[t] {
+		d := in.PathDist(t, p)
+		for _, m := range in.FeasMod[instance.PathKey{T: t, P: p}] {
+			if d <= in.R[m] {
+				rs = append(rs, Route{p, m})
+			}
+		}
+	}
+	sort.SliceStable(rs, func(a, b int) bool { return Slots(in, t, rs[a]) < Slots(in, t, rs[b]) })
+	return rs
+}
+
+// Slots is N_req of route r of t.
+func Slots(in *instance.Instance, t instance.Demand, r Route) int {
+	return in.NReq[instance.ModKey{T: t, P: r.P, M: r.M}]
+}
+
+// Shortest routes every request over its shortest reach-feasible candidate
+// path with the modulation needing the fewest slots on it. Requests without
+// a feasible route are left out.
+func Shortest(in *instance.Instance) map[instance.Demand]Route {
+	rt := map[instance.Demand]Route{}
+	for _, t := range in.Traffic {
+		best, bestDist, ok := Route{}, units.Distance(0), false
+		for _, r := range Candidates(in, t) {
+			d := in.PathDist(t, r.P)
+			if !ok || d < bestDist || d == bestDist && Slots(in, t, r) < Slots(in, t, best) {
+				best, bestDist, ok = r, d, true
+			}
+		}
+		if ok {
+			rt[t] = best
+		}
+	}
+	return rt
+}
+
+// FirstFitDecreasing assigns the requests, largest first, to the lowest
+// block that keeps the guard band to every block on a shared link, trying
+// their candidate routes from the cheapest. Blocks must end by limit slots;
+// requests that do not fit are rejected. Zones are ignored.
+func FirstFitDecreasing(in *instance.Instance, limit int) Plan {
+	return firstFitDecreasing(in, candidates(in), func(p Plan, t instance.Demand, r Route) (Assignment, bool) {
+		return p.LowestFit(in, t, r, limit)
+	})
+}
+
+// FirstFitDecreasingOn is FirstFitDecreasing restricted to the routes of
+// rt; requests without a route are rejected.
+func FirstFitDecreasingOn(in *instance.Instance, rt map[instance.Demand]Route, limit int) Plan {
+	return firstFitDecreasing(in, func(t instance.Demand) []Route {
+		if r, ok := rt[t]; ok {
+			return []Route{r}
+		}
+		return nil
+	}, func(p Plan, t instance.Demand, r Route) (Assignment, bool) {
+		return p.LowestFit(in, t, r, limit)
+	})
+}
+
+// FirstFitDecreasingZones is FirstFitDecreasing with every block inside
+// one zone: the lowest zone that has room, at the lowest start in it.
+func FirstFitDecreasingZones(in *instance.Instance) Plan {
+	zones := spectrum.Zones(in)
+	return firstFitDecreasing(in, candidates(in), func(p Plan, t instance.Demand, r Route) (Assignment, bool) {
+		for _, z := range zones {
+			if a, ok := p.lowestFit(in, t, r, z.Lo, z.Hi); ok {
+				return a, true
+			}
+		}
+		return Assignment{}, false
+	})
+}
+
+func candidates(in *instance.Instance) func(instance.Demand) []Route {
+	return func(t instance.Demand) []Route { return Candidates(in, t) }
+}
+
+func firstFitDecreasing(in *instance.Instance, routes func(instance.Demand) []Route, fit func(Plan, instance.Demand, Route) (Assignment, bool)) Plan {
+	type req struct {
+		t  instance.Demand
+		rs []Route
+	}
+	var reqs []req
+	for _, t := range in.Traffic {
+		if rs := routes(t); len(rs) > 0 {
+			reqs = append(reqs, req{t, rs})
+		}
+	}
+	sort.SliceStable(reqs, func(a, b int) bool {
+		return Slots(in, reqs[a].t, reqs[a].rs[0]) > Slots(in, reqs[b].t, reqs[b].rs[0])
+	})
+	var p Plan
+	for _, q := range reqs {
+		for _, r := range q.rs {
+			if a, ok := fit(p, q.t, r); ok {
+				p = append(p, a)
+				break
+			}
+		}
+	}
+	p.Sort(in)
+	return p
+}
+
+// LowestFit returns t on route r at the lowest start compatible with p and
+// ending by limit.
+func (p Plan) LowestFit(in *instance.Instance, t instance.Demand, r Route, limit int) (Assignment, bool) {
+	return p.lowestFit(in, t, r, 0, limit)
+}
+
+// lowestFit is LowestFit starting no lower than from.
+func (p Plan) lowestFit(in *instance.Instance, t instance.Demand, r Route, from, limit int) (Assignment, bool) {
+	a := Assignment{T: t, Route: r, Start: from, Slots: Slots(in, t, r)}
+	var shared []Assignment
+	for _, b := range p {
+		if Share(in, a, b) {
+			shared = append(shared, b)
+		}
+	}
+	sort.Slice(shared, func(i, j int) bool { return shared[i].Start < shared[j].Start })
+	// Every start below b.End()+G collides with an overlapping b, so jumping
+	// past it never skips a feasible start. Repeat until nothing collides.
+	for moved := true; moved; {
+		moved = false
+		for _, b := range shared {
+			if !Apart(a, b, in.G) {
+				a.Start = b.End() + in.G
+				moved = true
+			}
+		}
+	}
+	if a.End() > limit {
+		return Assignment{}, false
+	}
+	return a, true
+}
+
+// SideSplit is the connection-type threshold of zone-FLF: requests needing
+// at most this many slots are allocated from the left edge of a zone, the
+// others from the right. It is the mean N_req over all reach-feasible
+// (t,p,m), as in the simulator.
+func SideSplit(in *instance.Instance) int {
+	sum, n := 0, 0
+	for _, t := range in.Traffic {
+		for _, r := range Candidates(in, t) {
+			sum += Slots(in, t, r)
+			n++
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / n
+}
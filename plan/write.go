@@ -0,0 +1,24 @@
+package plan
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+)
+
+// Write prints p as a table followed by its summary figures.
+func (p Plan) Write(w io.Writer, in *instance.Instance) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "request\tclass\tpath\tmod\tslots\tblock")
+	for _, a := range p {
+		fmt.Fprintf(tw, "%v\t%s\t%s\t%s\t%d\t[%d,%d)\n", a.T, in.ClassOf(a.T), a.P, a.M, a.Slots, a.Start, a.End())
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "accepted %d/%d  weight %g  S_max %d  slot-links %d\n",
+		len(p), len(in.Traffic), p.Weight(in), p.SMax(), p.SlotLinks(in))
+	return err
+}
@@ -0,0 +1,289 @@
+// Package robust plans for uncertain traffic with a Bertsimas–Sim budget of
+// uncertainty. Every request t may carry up to T_sd + T_hat instead of its
+// forecast T_sd, which widens its block by the deviation
+//
+//	N_hat[t,p,m] = ceil((T_sd + T_hat) / (C * e_m)) - N_req[t,p,m],
+//
+// and at most Gamma requests deviate at once (Gamma may be fractional: one
+// more request then deviates by that fraction). The spectrum-size
+// constraints, one per link,
+//
+//	sum over routes k using l of (N_req[k] + G) UseMod[k] <= N_slots + G,
+//
+// get the robust counterpart
+//
+//	sum (N_req[k] + G) UseMod[k] + Gamma z[l] + sum p[l,k] <= N_slots + G
+//	p[l,k] + z[l] >= N_hat[k] UseMod[k],  p, z >= 0,
+//
+// the LP dual of the worst-case deviation of at most Gamma blocks on l.
+// A robust plan fixes acceptance, routes and modulations; its spectrum is
+// assigned for the forecast and re-assigned when traffic deviates. The
+// rows only bound the slots used on every link, which does not guarantee
+// that a re-assignment exists, so Solve verifies the plan against the
+// deviation patterns within the budget and reports it robust only when
+// every one of them is proven assignable.
+package robust
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/dilwar-crnlab/hpsr_2025/conflict"
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+	"github.com/dilwar-crnlab/hpsr_2025/milp"
+	"github.com/dilwar-crnlab/hpsr_2025/model"
+	"github.com/dilwar-crnlab/hpsr_2025/plan"
+	"github.com/dilwar-crnlab/hpsr_2025/spectrum"
+)
+
+// Deviation returns N_hat for every (t,p,m), with the slot efficiencies of
+// the modulations inferred from N_req by instance.Efficiency.
+func Deviation(in *instance.Instance) map[instance.ModKey]int {
+	eff := in.Efficiency()
+	dev := map[instance.ModKey]int{}
+	for k, n := range in.NReq {
+		hi := int(math.Ceil(float64((in.T[k.T]+in.THat[k.T])/in.C)/eff[k.M] - 1e-9))
+		if hi > n {
+			dev[k] = hi - n
+		}
+	}
+	return dev
+}
+
+// Options controls the robust planner.
+type Options struct {
+	Gamma     float64
+	NodeLimit int       // branch-and-bound nodes, <= 0 for no limit
+	Budget    int       // nodes of the spectrum assignment search
+	Patterns  int       // deviation patterns verified, <= 0 for no limit
+	Deadline  time.Time // zero for none
+}
+
+// Result is a robust plan.
+type Result struct {
+	Status milp.Status
+	Routes map[instance.Demand]plan.Route // accepted requests
+	Weight float64                        // accepted weight
+	// Plan is the spectrum assignment of Routes for the forecast traffic.
+	// Requests missing from it could not be assigned.
+	Plan plan.Plan
+	// Check is the verification of Routes against the deviation
+	// patterns. Robust is set when Plan assigns every route and every
+	// pattern was tried and proven assignable.
+	Check  Check
+	Robust bool
+}
+
+type route struct {
+	t instance.Demand
+	r plan.Route
+	v int // UseMod variable
+}
+
+// Build generates the robust MILP of in for budget gamma. Routes whose
+// block does not fit in any zone are left out, as are routes whose
+// deviated block does not when gamma >= 1, since a single request may
+// then deviate alone.
+func Build(in *instance.Instance, dev map[instance.ModKey]int, gamma float64) (*model.MILP, []route) {
+	m := model.NewMILP("robust")
+	widest := 0
+	for _, z := range spectrum.Zones(in) {
+		widest = max(widest, z.Width())
+	}
+	var rs []route
+	onLink := map[instance.Link][]route{}
+	for _, t := range in.Traffic {
+		var terms []model.Term
+		for _, r := range plan.Candidates(in, t) {
+			k := instance.ModKey{T: t, P: r.P, M: r.M}
+			n := in.NReq[k]
+			if n > widest || gamma >= 1 && n+dev[k] > widest {
+				continue
+			}
+			if len(terms) == 0 {
+				a := m.AddVar(fmt.Sprintf("Accept(%s,%s)", t.S, t.D), model.Binary, 0, 1)
+				m.Maximize(model.Term{Var: a, Coef: in.Weight(t)})
+				terms = append(terms, model.Term{Var: a, Coef: -1})
+			}
+			v := m.AddVar(fmt.Sprintf("UseMod(%s,%s,%s,%s)", t.S, t.D, r.P, r.M), model.Binary, 0, 1)
+			// Ties go to fewer slots.
+			m.Maximize(model.Term{Var: v, Coef: -1e-4 * in.Weight(t) * float64(n) / float64(in.NSlots)})
+			terms = append(terms, model.Term{Var: v, Coef: 1})
+			rt := route{t, r, v}
+			rs = append(rs, rt)
+			for _, l := range plan.Links(in, t, r) {
+				onLink[l] = append(onLink[l], rt)
+			}
+		}
+		if len(terms) > 0 {
+			m.AddRow(fmt.Sprintf("OneRoute(%s,%s)", t.S, t.D), terms, model.EQ, 0)
+		}
+	}
+	for _, l := range in.Links {
+		var cap []model.Term
+		z := -1
+		for _, rt := range onLink[l] {
+			k := instance.ModKey{T: rt.t, P: rt.r.P, M: rt.r.M}
+			cap = append(cap, model.Term{Var: rt.v, Coef: float64(in.NReq[k] + in.G)})
+			if dev[k] == 0 || gamma == 0 {
+				continue
+			}
+			if z < 0 {
+				z = m.AddVar(fmt.Sprintf("z(%s)", l), model.Continuous, 0, float64(maxDev(dev)))
+				cap = append(cap, model.Term{Var: z, Coef: gamma})
+			}
+			p := m.AddVar(fmt.Sprintf("p(%s,%s,%s,%s,%s)", l, rt.t.S, rt.t.D, rt.r.P, rt.r.M), model.Continuous, 0, float64(dev[k]))
+			cap = append(cap, model.Term{Var: p, Coef: 1})
+			m.AddRow(fmt.Sprintf("Protect(%s,%s,%s,%s,%s)", l, rt.t.S, rt.t.D, rt.r.P, rt.r.M),
+				[]model.Term{{Var: p, Coef: 1}, {Var: z, Coef: 1}, {Var: rt.v, Coef: -float64(dev[k])}}, model.GE, 0)
+		}
+		if len(cap) > 0 {
+			m.AddRow(fmt.Sprintf("Capacity(%s)", l), cap, model.LE, float64(in.NSlots+in.G))
+		}
+	}
+	return m, rs
+}
+
+func maxDev(dev map[instance.ModKey]int) int {
+	d := 0
+	for _, v := range dev {
+		d = max(d, v)
+	}
+	return d
+}
+
+// Solve computes a plan of in for the budget of uncertainty and verifies
+// it with Verify.
+func Solve(in *instance.Instance, opt Options) Result {
+	dev := Deviation(in)
+	m, rs := Build(in, dev, opt.Gamma)
+	r := milp.Solve(m, milp.Options{NodeLimit: opt.NodeLimit, Deadline: opt.Deadline})
+	res := Result{Status: r.Status, Routes: map[instance.Demand]plan.Route{}}
+	if r.X == nil {
+		return res
+	}
+	for _, rt := range rs {
+		if r.X[rt.v] > 0.5 {
+			res.Routes[rt.t] = rt.r
+			res.Weight += in.Weight(rt.t)
+		}
+	}
+	res.Plan = assign(in, res.Routes, opt.Budget)
+	limit := opt.Patterns
+	if limit <= 0 {
+		limit = math.MaxInt
+	}
+	res.Check = Verify(in, res.Routes, opt.Gamma, limit, opt.Budget)
+	res.Robust = len(res.Plan) == len(res.Routes) && !res.Check.Truncated &&
+		res.Check.Failed == 0 && res.Check.Undecided == 0
+	return res
+}
+
+// assign lays out the spectrum of the routes: the exact search, or DSatur
+// when no assignment of every route is found.
+func assign(in *instance.Instance, routes map[instance.Demand]plan.Route, budget int) plan.Plan {
+	g := conflict.Build(in, routes)
+	p, found, _ := g.Exact(budget)
+	if !found {
+		p = g.DSatur()
+	}
+	p.Sort(in)
+	return p
+}
+
+// Check is the outcome of Verify.
+type Check struct {
+	Realizations int               // deviation patterns tried
+	Failed       int               // patterns proven to have no spectrum assignment
+	Undecided    int               // patterns the search gave up on within budget
+	Worst        []instance.Demand // deviating requests of the first failure
+	Truncated    bool              // more patterns exist than were tried
+}
+
+// Verify re-assigns the spectrum of routes for deviation patterns within
+// budget gamma: every set of floor(gamma) deviating requests plus, for a
+// fractional gamma, one more request deviating by the fraction. The
+// capacity rows guarantee that no link is overloaded, but a conflict graph
+// can still need more spectrum than its busiest link, so patterns may
+// fail. A pattern fails only when the search proves that it has no
+// assignment; one left undecided after budget nodes is counted apart. At
+// most limit patterns are tried.
+func Verify(in *instance.Instance, routes map[instance.Demand]plan.Route, gamma float64, limit, budget int) Check {
+	var ts []instance.Demand
+	for t := range routes {
+		ts = append(ts, t)
+	}
+	sort.Slice(ts, func(a, b int) bool { return ts[a].String() < ts[b].String() })
+	full := int(gamma)
+	frac := gamma - float64(full)
+	full = min(full, len(ts))
+	var c Check
+	try := func(set []instance.Demand, extra int) bool {
+		if c.Realizations >= limit {
+			c.Truncated = true
+			return false
+		}
+		c.Realizations++
+		r := in.Clone()
+		for _, t := range set {
+			r.T[t] += r.THat[t]
+		}
+		if extra >= 0 {
+			r.T[ts[extra]] += r.THat[ts[extra]].Scale(frac)
+		}
+		rederive(in, r)
+		_, found, proven := conflict.Build(r, routes).Feasible(budget)
+		switch {
+		case found:
+		case !proven:
+			c.Undecided++
+		default:
+			c.Failed++
+			if c.Worst == nil {
+				c.Worst = append([]instance.Demand(nil), set...)
+				if extra >= 0 {
+					c.Worst = append(c.Worst, ts[extra])
+				}
+			}
+		}
+		return true
+	}
+	var rec func(from int, set []instance.Demand, chosen map[int]bool) bool
+	rec = func(from int, set []instance.Demand, chosen map[int]bool) bool {
+		if len(set) == full {
+			if frac == 0 || full == len(ts) {
+				return try(set, -1)
+			}
+			for e := range ts {
+				if !chosen[e] && !try(set, e) {
+					return false
+				}
+			}
+			return true
+		}
+		for i := from; i < len(ts); i++ {
+			chosen[i] = true
+			ok := rec(i+1, append(set, ts[i]), chosen)
+			delete(chosen, i)
+			if !ok {
+				return false
+			}
+		}
+		return true
+	}
+	rec(0, nil, map[int]bool{})
+	return c
+}
+
+// rederive recomputes the N_req of r, whose T_sd differ from those of in,
+// with the slot efficiencies of in.
+func rederive(in, r *instance.Instance) {
+	eff := in.Efficiency()
+	for k := range r.NReq {
+		if r.T[k.T] != in.T[k.T] {
+			r.NReq[k] = max(in.NReq[k], r.Slots(k.T, eff[k.M]))
+		}
+	}
+}
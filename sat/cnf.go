@@ -0,0 +1,132 @@
+// Package sat encodes the acceptance problem of an instance in CNF and
+// weighted CNF, writes them in DIMACS format for external SAT and MaxSAT
+// solvers, and solves small encodings with a built-in CDCL solver.
+package sat
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// Lit is a literal: variable v (0-based) is 2v, its negation 2v+1.
+type Lit int32
+
+// Pos and Neg return the literals of variable v.
+func Pos(v int) Lit { return Lit(2 * v) }
+func Neg(v int) Lit { return Lit(2*v + 1) }
+
+// Var is the variable of l.
+func (l Lit) Var() int { return int(l >> 1) }
+
+// Not is the negation of l.
+func (l Lit) Not() Lit { return l ^ 1 }
+
+// Sign reports whether l is negative.
+func (l Lit) Sign() bool { return l&1 == 1 }
+
+// Dimacs is l in DIMACS notation.
+func (l Lit) Dimacs() int {
+	if l.Sign() {
+		return -(l.Var() + 1)
+	}
+	return l.Var() + 1
+}
+
+// Soft is a weighted soft clause.
+type Soft struct {
+	Lits   []Lit
+	Weight int
+}
+
+// CNF is a set of hard clauses and, for MaxSAT, weighted soft clauses.
+type CNF struct {
+	Vars    int
+	Names   []string // optional, per variable
+	Clauses [][]Lit
+	Soft    []Soft
+}
+
+// NewVar adds a variable and returns it.
+func (f *CNF) NewVar(name string) int {
+	f.Vars++
+	f.Names = append(f.Names, name)
+	return f.Vars - 1
+}
+
+// Add adds a hard clause.
+func (f *CNF) Add(lits ...Lit) {
+	f.Clauses = append(f.Clauses, append([]Lit(nil), lits...))
+}
+
+// AtMostOne adds clauses allowing at most one of lits to be true, pairwise
+// for short lists and with Sinz's sequential counter otherwise.
+func (f *CNF) AtMostOne(lits []Lit) {
+	n := len(lits)
+	if n <= 5 {
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				f.Add(lits[i].Not(), lits[j].Not())
+			}
+		}
+		return
+	}
+	s := make([]Lit, n-1)
+	for i := range s {
+		s[i] = Pos(f.NewVar(""))
+	}
+	f.Add(lits[0].Not(), s[0])
+	for i := 1; i < n-1; i++ {
+		f.Add(lits[i].Not(), s[i])
+		f.Add(s[i-1].Not(), s[i])
+		f.Add(lits[i].Not(), s[i-1].Not())
+	}
+	f.Add(lits[n-1].Not(), s[n-2].Not())
+}
+
+// WriteDIMACS writes the hard clauses in DIMACS CNF format, naming the
+// variables in comment lines.
+func (f *CNF) WriteDIMACS(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	f.writeNames(bw)
+	fmt.Fprintf(bw, "p cnf %d %d\n", f.Vars, len(f.Clauses))
+	for _, c := range f.Clauses {
+		writeClause(bw, "", c)
+	}
+	return bw.Flush()
+}
+
+// WriteWCNF writes hard and soft clauses in the weighted DIMACS format
+// "p wcnf VARS CLAUSES TOP", hard clauses carrying weight TOP.
+func (f *CNF) WriteWCNF(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	top := 1
+	for _, s := range f.Soft {
+		top += s.Weight
+	}
+	f.writeNames(bw)
+	fmt.Fprintf(bw, "p wcnf %d %d %d\n", f.Vars, len(f.Clauses)+len(f.Soft), top)
+	for _, c := range f.Clauses {
+		writeClause(bw, fmt.Sprint(top, " "), c)
+	}
+	for _, s := range f.Soft {
+		writeClause(bw, fmt.Sprint(s.Weight, " "), s.Lits)
+	}
+	return bw.Flush()
+}
+
+func (f *CNF) writeNames(w io.Writer) {
+	for v, n := range f.Names {
+		if n != "" {
+			fmt.Fprintf(w, "c %d %s\n", v+1, n)
+		}
+	}
+}
+
+func writeClause(w io.Writer, prefix string, c []Lit) {
+	fmt.Fprint(w, prefix)
+	for _, l := range c {
+		fmt.Fprint(w, l.Dimacs(), " ")
+	}
+	fmt.Fprintln(w, 0)
+}
@@ -0,0 +1,172 @@
+package sat
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+	"github.com/dilwar-crnlab/hpsr_2025/plan"
+	"github.com/dilwar-crnlab/hpsr_2025/spectrum"
+)
+
+// Options selects the constraints of the encoding.
+type Options struct {
+	// All makes acceptance of every routable request a hard constraint
+	// ("can all of TRAFFIC fit in N_slots?"); otherwise acceptance is soft
+	// with the class weight of the request.
+	All bool
+	// Sides enforces the zone-FLF order: within a zone, a block of the
+	// right-hand connection type never lies below a block of the
+	// left-hand type on a shared link.
+	Sides bool
+}
+
+// Encoding is the CNF of an instance with the meaning of its variables.
+type Encoding struct {
+	*CNF
+	In *instance.Instance
+	// Scale is the soft clause weight of one unit of class weight.
+	Scale  float64
+	accept map[instance.Demand]int
+	starts []start // per variable index, for the x variables
+	isX    []bool
+}
+
+type start struct {
+	t instance.Demand
+	r plan.Route
+	s int
+}
+
+// Encode builds the CNF of the acceptance problem of in. Variable
+// Accept(t) tells whether t is accepted and x(t,p,m,s) whether it starts
+// at slot s on path p with modulation m, for starts whose N_req block lies
+// in a zone. A block occupies [s, s+N_req+G) clipped at N_slots, and at
+// most one block may occupy a slot of a link.
+func Encode(in *instance.Instance, opt Options) *Encoding {
+	e := &Encoding{CNF: &CNF{}, In: in, Scale: 1, accept: map[instance.Demand]int{}}
+	zones := spectrum.Zones(in)
+	split := plan.SideSplit(in)
+	type block struct {
+		x       Lit
+		t       instance.Demand
+		zone    int
+		s, n    int
+		left    bool
+		linkSet map[instance.Link]bool
+	}
+	occupy := map[instance.Link][][]Lit{}
+	var blocks []block
+	weights := map[instance.Demand]float64{}
+	for _, t := range in.Traffic {
+		rs := plan.Candidates(in, t)
+		if len(rs) == 0 {
+			continue
+		}
+		a := e.newVar(fmt.Sprintf("Accept(%s,%s)", t.S, t.D), start{}, false)
+		e.accept[t] = a
+		weights[t] = in.Weight(t)
+		some := []Lit{Neg(a)}
+		var own []Lit
+		for _, r := range rs {
+			n := plan.Slots(in, t, r)
+			links := plan.Links(in, t, r)
+			for zi, z := range zones {
+				for s := z.Lo; s+n <= z.Hi; s++ {
+					x := Pos(e.newVar(fmt.Sprintf("x(%s,%s,%s,%s,%d)", t.S, t.D, r.P, r.M, s), start{t, r, s}, true))
+					e.Add(x.Not(), Pos(a))
+					some = append(some, x)
+					own = append(own, x)
+					b := block{x: x, t: t, zone: zi, s: s, n: n, left: n <= split, linkSet: map[instance.Link]bool{}}
+					for _, l := range links {
+						b.linkSet[l] = true
+						if occupy[l] == nil {
+							occupy[l] = make([][]Lit, in.NSlots)
+						}
+						for q := s; q < min(s+n+in.G, in.NSlots); q++ {
+							occupy[l][q] = append(occupy[l][q], x)
+						}
+					}
+					blocks = append(blocks, b)
+				}
+			}
+		}
+		e.Add(some...)
+		e.AtMostOne(own)
+		if opt.All {
+			e.Add(Pos(a))
+		}
+	}
+	for _, l := range in.Links {
+		for _, xs := range occupy[l] {
+			if len(xs) > 1 {
+				e.AtMostOne(xs)
+			}
+		}
+	}
+	if opt.Sides {
+		for _, a := range blocks {
+			if !a.left {
+				continue
+			}
+			for _, b := range blocks {
+				if b.left || b.t == a.t || b.zone != a.zone || b.s >= a.s || !sharesAny(a.linkSet, b.linkSet) {
+					continue
+				}
+				e.Add(a.x.Not(), b.x.Not())
+			}
+		}
+	}
+	if !opt.All {
+		e.Scale = weightScale(weights)
+		for _, t := range in.Traffic {
+			if a, ok := e.accept[t]; ok {
+				e.Soft = append(e.Soft, Soft{[]Lit{Pos(a)}, int(math.Round(weights[t] * e.Scale))})
+			}
+		}
+	}
+	return e
+}
+
+func (e *Encoding) newVar(name string, s start, isX bool) int {
+	v := e.NewVar(name)
+	for len(e.starts) <= v {
+		e.starts = append(e.starts, start{})
+		e.isX = append(e.isX, false)
+	}
+	e.starts[v], e.isX[v] = s, isX
+	return v
+}
+
+func sharesAny(a, b map[instance.Link]bool) bool {
+	for l := range a {
+		if b[l] {
+			return true
+		}
+	}
+	return false
+}
+
+// weightScale returns 1 when all class weights are integers and 100
+// otherwise, so that soft clause weights are integral.
+func weightScale(ws map[instance.Demand]float64) float64 {
+	for _, w := range ws {
+		if w != math.Trunc(w) {
+			return 100
+		}
+	}
+	return 1
+}
+
+// Decode turns a model of the encoding into a plan.
+func (e *Encoding) Decode(model []bool) plan.Plan {
+	var p plan.Plan
+	for v, isX := range e.isX {
+		if isX && v < len(model) && model[v] {
+			st := e.starts[v]
+			p = append(p, plan.Assignment{T: st.t, Route: st.r, Start: st.s, Slots: plan.Slots(e.In, st.t, st.r)})
+		}
+	}
+	p.Sort(e.In)
+	return p
+}
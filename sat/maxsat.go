@@ -0,0 +1,130 @@
+package sat
+
+import "time"
+
+// MaxResult is the outcome of SolveMax.
+type MaxResult struct {
+	Status    Status // Satisfiable once a model of the hard clauses is known
+	Model     []bool
+	Weight    int // total weight of the satisfied soft clauses
+	Optimal   bool
+	Calls     int // SAT calls
+	Conflicts int
+}
+
+// SolveMax maximises the weight of the satisfied soft clauses of f subject
+// to its hard clauses by linear SAT-UNSAT search: every soft clause enters
+// a totalizer once per unit of weight, and after a model of weight w the
+// output "at least w+1" is asserted until the clauses become unsatisfiable.
+// The totalizer grows with the total weight, so this suits small weights
+// such as class priorities. A zero deadline means no time limit.
+func SolveMax(f *CNF, deadline time.Time) MaxResult {
+	s, outs, total := newMax(f)
+	s.Deadline = deadline
+	var r MaxResult
+	for {
+		r.Calls++
+		st := s.Solve()
+		r.Conflicts = s.Conflicts
+		switch st {
+		case Unknown:
+			return r
+		case Unsatisfiable:
+			r.Optimal = r.Status == Satisfiable
+			if !r.Optimal {
+				r.Status = Unsatisfiable
+			}
+			return r
+		}
+		m := s.Model()
+		r.Status, r.Model, r.Weight = Satisfiable, m[:f.Vars], weight(f, m)
+		if r.Weight >= total {
+			r.Optimal = true
+			return r
+		}
+		s.AddClause(outs[r.Weight])
+	}
+}
+
+// newMax loads the hard clauses of f into a solver and adds a totalizer
+// over its soft clauses, each entered once per unit of weight. It returns
+// the totalizer outputs, out[i] meaning "weight at least i+1", and the total
+// soft weight.
+func newMax(f *CNF) (s *Solver, out []Lit, total int) {
+	s = NewSolver(f)
+	next := f.Vars
+	newVar := func() Lit {
+		next++
+		s.grow(next)
+		return Pos(next - 1)
+	}
+
+	// A soft clause counts through a literal that implies it.
+	var inputs []Lit
+	for _, c := range f.Soft {
+		l := c.Lits[0]
+		if len(c.Lits) > 1 {
+			l = newVar()
+			s.AddClause(append([]Lit{l.Not()}, c.Lits...)...)
+		}
+		for i := 0; i < c.Weight; i++ {
+			inputs = append(inputs, l)
+		}
+		total += c.Weight
+	}
+	return s, totalizer(s, inputs, newVar), total
+}
+
+// weight sums the weights of the soft clauses of f satisfied by m.
+func weight(f *CNF, m []bool) int {
+	w := 0
+	for _, c := range f.Soft {
+		for _, l := range c.Lits {
+			if m[l.Var()] != l.Sign() {
+				w += c.Weight
+				break
+			}
+		}
+	}
+	return w
+}
+
+// totalizer adds a totalizer over in to s and returns its outputs: out[i]
+// is true exactly when at least i+1 literals of in are true.
+func totalizer(s *Solver, in []Lit, newVar func() Lit) []Lit {
+	if len(in) <= 1 {
+		return in
+	}
+	a := totalizer(s, in[:len(in)/2], newVar)
+	b := totalizer(s, in[len(in)/2:], newVar)
+	out := make([]Lit, len(a)+len(b))
+	for i := range out {
+		out[i] = newVar()
+	}
+	// at(x, i) is "at least i of x": always true for i = 0 and always
+	// false beyond len(x), represented by a nil clause part.
+	at := func(x []Lit, i int, neg bool) []Lit {
+		if i == 0 || i > len(x) {
+			return nil
+		}
+		if neg {
+			return []Lit{x[i-1].Not()}
+		}
+		return []Lit{x[i-1]}
+	}
+	for i := 0; i <= len(a); i++ {
+		for j := 0; j <= len(b); j++ {
+			if i+j > 0 {
+				// a >= i and b >= j imply out >= i+j.
+				c := append(at(a, i, true), at(b, j, true)...)
+				s.AddClause(append(c, out[i+j-1])...)
+			}
+			if i+j < len(out) {
+				// a < i+1 and b < j+1 imply out < i+j+1.
+				c := append(at(a, i+1, false), at(b, j+1, false)...)
+				s.AddClause(append(c, out[i+j].Not())...)
+			}
+		}
+	}
+	return out
+}
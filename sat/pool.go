@@ -0,0 +1,116 @@
+package sat
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/dilwar-crnlab/hpsr_2025/plan"
+)
+
+// Distinctness keys of Pool: what sets two plans apart besides their
+// accepted requests.
+const (
+	DistinctRoutes   = "routes"   // the path or modulation of a request
+	DistinctStarts   = "starts"   // also the start slot of a block
+	DistinctRequests = "requests" // nothing else
+)
+
+// PoolResult is the outcome of Pool.
+type PoolResult struct {
+	Plans []plan.Plan // in the order found
+	Best  float64     // optimal accepted weight
+	// Complete reports that every plan within the gap was enumerated.
+	Complete bool
+}
+
+// Pool enumerates up to n plans of e whose accepted weight is within gap
+// of the optimum and that are distinct under key. It first solves the
+// MaxSAT problem, then asserts "weight at least optimum - gap" on the
+// totalizer and blocks each plan found with a no-good clause over its
+// Accept(t) variables and the choices key distinguishes: a variable per
+// request, path and modulation implied by its x(t,p,m,s) for
+// DistinctRoutes, the x(t,p,m,s) themselves for DistinctStarts. Plans
+// differing only in auxiliary variables are thereby not repeated, and with
+// DistinctRoutes neither are plans that only shift blocks. n <= 0 means no
+// limit.
+func Pool(e *Encoding, n int, gap float64, key string, deadline time.Time) PoolResult {
+	var res PoolResult
+	r := SolveMax(e.CNF, deadline)
+	if r.Status != Satisfiable || !r.Optimal {
+		return res
+	}
+	res.Best = float64(r.Weight) / e.Scale
+	s, outs, _ := newMax(e.CNF)
+	s.Deadline = deadline
+	if least := r.Weight - int(math.Floor(gap*e.Scale+1e-9)); least > 0 {
+		s.AddClause(outs[least-1])
+	}
+	choice := e.choices(s, key)
+	for n <= 0 || len(res.Plans) < n {
+		switch s.Solve() {
+		case Unknown:
+			return res
+		case Unsatisfiable:
+			res.Complete = true
+			return res
+		}
+		m := s.Model()
+		res.Plans = append(res.Plans, e.Decode(m))
+		s.AddClause(e.block(m, choice)...)
+	}
+	return res
+}
+
+// choices returns the literal standing for the choice of every x variable
+// under key, adding the route variables of DistinctRoutes to s.
+func (e *Encoding) choices(s *Solver, key string) map[int]Lit {
+	choice := map[int]Lit{}
+	routes := map[start]Lit{}
+	for v, isX := range e.isX {
+		if !isX {
+			continue
+		}
+		st := e.starts[v]
+		switch key {
+		case DistinctRoutes:
+			k := start{t: st.t, r: st.r}
+			u, ok := routes[k]
+			if !ok {
+				s.grow(s.nVars + 1)
+				u = Pos(s.nVars - 1)
+				routes[k] = u
+			}
+			s.AddClause(Neg(v), u)
+			choice[v] = u
+		case DistinctStarts:
+			choice[v] = Pos(v)
+		case DistinctRequests:
+			choice[v] = Pos(e.accept[st.t])
+		default:
+			panic(fmt.Sprintf("sat: unknown distinctness key %q", key))
+		}
+	}
+	return choice
+}
+
+// block returns a clause excluding the plan of model: every later model
+// drops one of its choices or accepts another request. The choices are
+// read off the x variables, since a route variable may be true without
+// any of its blocks.
+func (e *Encoding) block(model []bool, choice map[int]Lit) []Lit {
+	var c []Lit
+	seen := map[Lit]bool{}
+	for v, isX := range e.isX {
+		if l := choice[v]; isX && model[v] && !seen[l] {
+			seen[l] = true
+			c = append(c, l.Not())
+		}
+	}
+	for _, t := range e.In.Traffic {
+		if a, ok := e.accept[t]; ok && !model[a] {
+			c = append(c, Pos(a))
+		}
+	}
+	return c
+}
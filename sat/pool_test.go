@@ -0,0 +1,55 @@
+package sat
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+)
+
+func TestPool(t *testing.T) {
+	in, err := instance.Load("../testdata/crankback.dat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// One request with two paths and two modulations, each block fitting
+	// at 7 or 5 starts in either of two 8-slot zones.
+	tests := []struct {
+		key   string
+		plans int
+	}{
+		{DistinctRoutes, 4},
+		{DistinctStarts, 48},
+		{DistinctRequests, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			res := Pool(Encode(in, Options{}), 0, 0, tt.key, time.Now().Add(time.Minute))
+			if !res.Complete || len(res.Plans) != tt.plans {
+				t.Fatalf("%d plans (complete %v), want %d", len(res.Plans), res.Complete, tt.plans)
+			}
+			if res.Best != 1 {
+				t.Errorf("optimum %g, want 1", res.Best)
+			}
+			seen := map[string]bool{}
+			for _, p := range res.Plans {
+				if err := p.Validate(in); err != nil {
+					t.Fatal(err)
+				}
+				if len(p) != 1 {
+					t.Fatalf("plan %v, want one block", p)
+				}
+				a := p[0]
+				k := a.P + "," + a.M
+				if tt.key == DistinctStarts {
+					k += fmt.Sprint(",", a.Start)
+				}
+				if tt.key != DistinctRequests && seen[k] {
+					t.Errorf("plan %v repeated", p)
+				}
+				seen[k] = true
+			}
+		})
+	}
+}
@@ -0,0 +1,353 @@
+package sat
+
+import "time"
+
+// Status is the answer of the solver.
+type Status int
+
+const (
+	Unknown Status = iota
+	Satisfiable
+	Unsatisfiable
+)
+
+func (s Status) String() string {
+	return [...]string{"unknown", "satisfiable", "unsatisfiable"}[s]
+}
+
+type clause struct {
+	lits   []Lit
+	learnt bool
+}
+
+// Solver is a CDCL SAT solver with two watched literals, first-UIP clause
+// learning, VSIDS-style activities, phase saving and Luby restarts. It
+// keeps every learnt clause, which is fine for the small encodings it is
+// meant for. Clauses may be added between calls to Solve.
+type Solver struct {
+	nVars    int
+	clauses  []*clause
+	watches  [][]*clause // by literal: clauses watching it
+	assign   []int8      // per variable: 0 unassigned, 1 true, -1 false
+	level    []int
+	reason   []*clause
+	trail    []Lit
+	trailLim []int
+	qhead    int
+	activity []float64
+	varInc   float64
+	phase    []bool
+	seen     []bool
+	unsat    bool
+
+	// Conflicts counts the conflicts of all calls to Solve.
+	Conflicts int
+	// Deadline stops Solve with Unknown when passed (zero for none).
+	Deadline time.Time
+	// MaxConflicts stops Solve with Unknown after that many conflicts
+	// (<= 0 for no limit).
+	MaxConflicts int
+}
+
+// NewSolver returns a solver loaded with the hard clauses of f.
+func NewSolver(f *CNF) *Solver {
+	s := &Solver{varInc: 1}
+	s.grow(f.Vars)
+	for _, c := range f.Clauses {
+		s.AddClause(c...)
+	}
+	return s
+}
+
+func (s *Solver) grow(n int) {
+	for s.nVars < n {
+		s.nVars++
+		s.watches = append(s.watches, nil, nil)
+		s.assign = append(s.assign, 0)
+		s.level = append(s.level, 0)
+		s.reason = append(s.reason, nil)
+		s.activity = append(s.activity, 0)
+		s.phase = append(s.phase, false)
+		s.seen = append(s.seen, false)
+	}
+}
+
+func (s *Solver) value(l Lit) int8 {
+	v := s.assign[l.Var()]
+	if l.Sign() {
+		return -v
+	}
+	return v
+}
+
+func (s *Solver) decisionLevel() int { return len(s.trailLim) }
+
+// AddClause adds a clause at decision level 0.
+func (s *Solver) AddClause(lits ...Lit) {
+	if s.unsat {
+		return
+	}
+	s.backtrack(0)
+	maxVar := 0
+	for _, l := range lits {
+		maxVar = max(maxVar, l.Var()+1)
+	}
+	s.grow(maxVar)
+	// Drop false and duplicate literals; skip satisfied clauses.
+	var c []Lit
+	has := map[Lit]bool{}
+	for _, l := range lits {
+		switch {
+		case s.value(l) == 1 || has[l.Not()]:
+			return
+		case s.value(l) == -1 || has[l]:
+			continue
+		}
+		has[l] = true
+		c = append(c, l)
+	}
+	switch len(c) {
+	case 0:
+		s.unsat = true
+	case 1:
+		s.enqueue(c[0], nil)
+		if s.propagate() != nil {
+			s.unsat = true
+		}
+	default:
+		s.attach(&clause{lits: c})
+	}
+}
+
+func (s *Solver) attach(c *clause) {
+	s.clauses = append(s.clauses, c)
+	s.watches[c.lits[0]] = append(s.watches[c.lits[0]], c)
+	s.watches[c.lits[1]] = append(s.watches[c.lits[1]], c)
+}
+
+func (s *Solver) enqueue(l Lit, from *clause) {
+	v := l.Var()
+	if l.Sign() {
+		s.assign[v] = -1
+	} else {
+		s.assign[v] = 1
+	}
+	s.level[v] = s.decisionLevel()
+	s.reason[v] = from
+	s.trail = append(s.trail, l)
+}
+
+// propagate performs unit propagation and returns a conflicting clause.
+func (s *Solver) propagate() *clause {
+	for s.qhead < len(s.trail) {
+		falseLit := s.trail[s.qhead].Not()
+		s.qhead++
+		ws := s.watches[falseLit]
+		i, j := 0, 0
+		for i < len(ws) {
+			c := ws[i]
+			i++
+			if c.lits[0] == falseLit {
+				c.lits[0], c.lits[1] = c.lits[1], c.lits[0]
+			}
+			if s.value(c.lits[0]) == 1 {
+				ws[j] = c
+				j++
+				continue
+			}
+			moved := false
+			for k := 2; k < len(c.lits); k++ {
+				if s.value(c.lits[k]) != -1 {
+					c.lits[1], c.lits[k] = c.lits[k], c.lits[1]
+					s.watches[c.lits[1]] = append(s.watches[c.lits[1]], c)
+					moved = true
+					break
+				}
+			}
+			if moved {
+				continue
+			}
+			ws[j] = c
+			j++
+			if s.value(c.lits[0]) == -1 {
+				j += copy(ws[j:], ws[i:])
+				s.watches[falseLit] = ws[:j]
+				s.qhead = len(s.trail)
+				return c
+			}
+			s.enqueue(c.lits[0], c)
+		}
+		s.watches[falseLit] = ws[:j]
+	}
+	return nil
+}
+
+// analyze derives the first-UIP clause of a conflict and the level to
+// jump back to.
+func (s *Solver) analyze(confl *clause) ([]Lit, int) {
+	learnt := []Lit{0}
+	pathC := 0
+	p := Lit(-1)
+	idx := len(s.trail) - 1
+	for {
+		start := 0
+		if p >= 0 {
+			start = 1
+		}
+		for _, q := range confl.lits[start:] {
+			v := q.Var()
+			if s.seen[v] || s.level[v] == 0 {
+				continue
+			}
+			s.seen[v] = true
+			s.bump(v)
+			if s.level[v] == s.decisionLevel() {
+				pathC++
+			} else {
+				learnt = append(learnt, q)
+			}
+		}
+		for !s.seen[s.trail[idx].Var()] {
+			idx--
+		}
+		p = s.trail[idx]
+		idx--
+		confl = s.reason[p.Var()]
+		s.seen[p.Var()] = false
+		pathC--
+		if pathC == 0 {
+			break
+		}
+	}
+	learnt[0] = p.Not()
+	back := 0
+	for i := 1; i < len(learnt); i++ {
+		if l := s.level[learnt[i].Var()]; l > back {
+			back = l
+			learnt[1], learnt[i] = learnt[i], learnt[1]
+		}
+	}
+	for _, l := range learnt {
+		s.seen[l.Var()] = false
+	}
+	return learnt, back
+}
+
+func (s *Solver) bump(v int) {
+	s.activity[v] += s.varInc
+	if s.activity[v] > 1e100 {
+		for i := range s.activity {
+			s.activity[i] *= 1e-100
+		}
+		s.varInc *= 1e-100
+	}
+}
+
+func (s *Solver) backtrack(level int) {
+	if s.decisionLevel() <= level {
+		return
+	}
+	for i := len(s.trail) - 1; i >= s.trailLim[level]; i-- {
+		v := s.trail[i].Var()
+		s.phase[v] = s.assign[v] == 1
+		s.assign[v] = 0
+		s.reason[v] = nil
+	}
+	s.trail = s.trail[:s.trailLim[level]]
+	s.trailLim = s.trailLim[:level]
+	s.qhead = len(s.trail)
+}
+
+// decide picks the unassigned variable of highest activity.
+func (s *Solver) decide() (Lit, bool) {
+	best := -1
+	for v := 0; v < s.nVars; v++ {
+		if s.assign[v] == 0 && (best < 0 || s.activity[v] > s.activity[best]) {
+			best = v
+		}
+	}
+	if best < 0 {
+		return 0, false
+	}
+	if s.phase[best] {
+		return Pos(best), true
+	}
+	return Neg(best), true
+}
+
+// luby returns the i-th element (from 0) of the Luby sequence.
+func luby(i int) int {
+	size, seq := 1, 0
+	for size < i+1 {
+		seq++
+		size = 2*size + 1
+	}
+	for size-1 != i {
+		size = (size - 1) / 2
+		seq--
+		i %= size
+	}
+	return 1 << seq
+}
+
+// Solve decides the clauses added so far. On Satisfiable, Model returns
+// the assignment found.
+func (s *Solver) Solve() Status {
+	if s.unsat {
+		return Unsatisfiable
+	}
+	s.backtrack(0)
+	if s.propagate() != nil {
+		s.unsat = true
+		return Unsatisfiable
+	}
+	start := s.Conflicts
+	for restart := 0; ; restart++ {
+		budget := 100 * luby(restart)
+		for {
+			if confl := s.propagate(); confl != nil {
+				s.Conflicts++
+				budget--
+				if s.decisionLevel() == 0 {
+					s.unsat = true
+					return Unsatisfiable
+				}
+				learnt, back := s.analyze(confl)
+				s.backtrack(back)
+				if len(learnt) == 1 {
+					s.enqueue(learnt[0], nil)
+				} else {
+					c := &clause{lits: learnt, learnt: true}
+					s.attach(c)
+					s.enqueue(learnt[0], c)
+				}
+				s.varInc *= 1 / 0.95
+				continue
+			}
+			if s.MaxConflicts > 0 && s.Conflicts-start >= s.MaxConflicts ||
+				!s.Deadline.IsZero() && time.Now().After(s.Deadline) {
+				s.backtrack(0)
+				return Unknown
+			}
+			if budget <= 0 {
+				s.backtrack(0)
+				break
+			}
+			l, ok := s.decide()
+			if !ok {
+				return Satisfiable
+			}
+			s.trailLim = append(s.trailLim, len(s.trail))
+			s.enqueue(l, nil)
+		}
+	}
+}
+
+// Model returns the truth value of every variable after Satisfiable.
+func (s *Solver) Model() []bool {
+	m := make([]bool, s.nVars)
+	for v := range m {
+		m[v] = s.assign[v] == 1
+	}
+	return m
+}
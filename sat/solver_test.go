@@ -0,0 +1,111 @@
+package sat
+
+import "testing"
+
+// pigeons puts every one of p pigeons into one of h holes, at most one
+// pigeon per hole, which is satisfiable if and only if p <= h.
+func pigeons(p, h int) *CNF {
+	f := &CNF{}
+	x := make([][]Lit, p)
+	for i := range x {
+		for range h {
+			x[i] = append(x[i], Pos(f.NewVar("")))
+		}
+		f.Add(x[i]...)
+	}
+	for j := range h {
+		var in []Lit
+		for i := range p {
+			in = append(in, x[i][j])
+		}
+		f.AtMostOne(in)
+	}
+	return f
+}
+
+// satisfies reports whether model satisfies every clause of f.
+func satisfies(f *CNF, model []bool) bool {
+	for _, c := range f.Clauses {
+		ok := false
+		for _, l := range c {
+			if model[l.Var()] != l.Sign() {
+				ok = true
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSolve(t *testing.T) {
+	// (a or b) and (not a or b) and (a or not b) forces a and b.
+	small := &CNF{Vars: 2}
+	small.Add(Pos(0), Pos(1))
+	small.Add(Neg(0), Pos(1))
+	small.Add(Pos(0), Neg(1))
+	contradiction := &CNF{Vars: 2}
+	contradiction.Add(Pos(0), Pos(1))
+	contradiction.Add(Neg(0), Pos(1))
+	contradiction.Add(Pos(0), Neg(1))
+	contradiction.Add(Neg(0), Neg(1))
+	unit := &CNF{Vars: 1}
+	unit.Add(Pos(0))
+	unit.Add(Neg(0))
+
+	tests := []struct {
+		name string
+		f    *CNF
+		want Status
+	}{
+		{"small", small, Satisfiable},
+		{"contradiction", contradiction, Unsatisfiable},
+		{"unit", unit, Unsatisfiable},
+		{"empty", &CNF{Vars: 3}, Satisfiable},
+		{"pigeons 3 in 3", pigeons(3, 3), Satisfiable},
+		{"pigeons 3 in 2", pigeons(3, 2), Unsatisfiable},
+		{"pigeons 7 in 7", pigeons(7, 7), Satisfiable},
+		{"pigeons 7 in 6", pigeons(7, 6), Unsatisfiable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewSolver(tt.f)
+			got := s.Solve()
+			if got != tt.want {
+				t.Fatalf("Solve() = %v, want %v", got, tt.want)
+			}
+			if got == Satisfiable && !satisfies(tt.f, s.Model()) {
+				t.Errorf("model %v violates a clause", s.Model())
+			}
+		})
+	}
+}
+
+func TestIncremental(t *testing.T) {
+	f := &CNF{Vars: 2}
+	f.Add(Pos(0), Pos(1))
+	s := NewSolver(f)
+	var blocked int
+	for s.Solve() == Satisfiable {
+		m := s.Model()
+		var block []Lit
+		for v, b := range m {
+			if b {
+				block = append(block, Neg(v))
+			} else {
+				block = append(block, Pos(v))
+			}
+		}
+		s.AddClause(block...)
+		if blocked++; blocked > 3 {
+			t.Fatal("more than 3 models of a or b")
+		}
+	}
+	if blocked != 3 {
+		t.Errorf("%d models of a or b, want 3", blocked)
+	}
+	if got := s.Solve(); got != Unsatisfiable {
+		t.Errorf("Solve() after blocking every model = %v", got)
+	}
+}
@@ -0,0 +1,205 @@
+// Package sensitivity varies instance parameters over ranges, re-derives
+// the data that depends on them and re-solves the instance at every point.
+package sensitivity
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+	"github.com/dilwar-crnlab/hpsr_2025/plan"
+	"github.com/dilwar-crnlab/hpsr_2025/solve"
+	"github.com/dilwar-crnlab/hpsr_2025/units"
+)
+
+// Parameters that can be swept:
+//
+//	N_slots  spectrum size; the zones are rescaled in proportion
+//	G        guard band in slots
+//	K        candidate paths per demand; PATHS are re-derived as the K
+//	         shortest paths, FEAS_MOD and N_req with them
+//	R        factor applied to the reach of every modulation; FEAS_MOD
+//	         and N_req are re-derived
+//	R[m]     reach of modulation m; FEAS_MOD and N_req are re-derived
+//
+// N_req of a path and modulation that did not exist in the instance is
+// ceil(T_sd / (C * e_m)) with e_m inferred by instance.Efficiency.
+const (
+	NSlots = "N_slots"
+	G      = "G"
+	K      = "K"
+	R      = "R"
+)
+
+// Param is a swept parameter and its values.
+type Param struct {
+	Name   string
+	Values []float64
+}
+
+// ParseParam parses NAME=LO:HI[:STEP] or NAME=V1,V2,... Values of R[m]
+// may carry a distance unit (R[16QAM]=400km:1.2Mm:100km) and values of
+// N_slots and G a slot or frequency unit (N_slots=2THz:4THz:500GHz).
+func ParseParam(s string) (Param, error) {
+	name, vals, ok := strings.Cut(s, "=")
+	if !ok {
+		return Param{}, fmt.Errorf("parameter %q: want NAME=LO:HI[:STEP] or NAME=V1,V2,...", s)
+	}
+	p := Param{Name: name}
+	switch {
+	case name == NSlots, name == G, name == K, name == R:
+	case strings.HasPrefix(name, "R[") && strings.HasSuffix(name, "]"):
+	default:
+		return Param{}, fmt.Errorf("parameter %q: unknown name %q (have N_slots, G, K, R, R[m])", s, name)
+	}
+	if parts := strings.Split(vals, ":"); len(parts) > 1 {
+		if len(parts) > 3 {
+			return Param{}, fmt.Errorf("parameter %q: want LO:HI[:STEP]", s)
+		}
+		nums := []float64{0, 0, 1}
+		for i, x := range parts {
+			v, err := parseValue(name, x)
+			if err != nil {
+				return Param{}, fmt.Errorf("parameter %q: %w", s, err)
+			}
+			nums[i] = v
+		}
+		if nums[2] <= 0 {
+			return Param{}, fmt.Errorf("parameter %q: step must be positive", s)
+		}
+		for i := 0; ; i++ {
+			// Rounding keeps decimal steps such as 0.1 readable.
+			v := math.Round((nums[0]+float64(i)*nums[2])*1e9) / 1e9
+			if v > nums[1]+1e-9*nums[2] {
+				break
+			}
+			p.Values = append(p.Values, v)
+		}
+	} else {
+		for _, x := range strings.Split(vals, ",") {
+			v, err := parseValue(name, x)
+			if err != nil {
+				return Param{}, fmt.Errorf("parameter %q: %w", s, err)
+			}
+			p.Values = append(p.Values, v)
+		}
+	}
+	if len(p.Values) == 0 {
+		return Param{}, fmt.Errorf("parameter %q: no values", s)
+	}
+	return p, nil
+}
+
+// parseValue parses one value of parameter name with its units.
+func parseValue(name, x string) (float64, error) {
+	switch {
+	case name == NSlots, name == G:
+		n, err := units.ParseSlots(x)
+		return float64(n), err
+	case strings.HasPrefix(name, "R["):
+		d, err := units.ParseDistance(x)
+		return float64(d), err
+	}
+	return strconv.ParseFloat(x, 64)
+}
+
+// Apply sets parameter name of in to v and re-derives the dependent data.
+// eff is the slot efficiency per modulation used for new N_req values.
+func Apply(in *instance.Instance, eff map[string]float64, name string, v float64) error {
+	integer := func() (int, error) {
+		n := int(math.Round(v))
+		if math.Abs(v-float64(n)) > 1e-9 || n < 0 {
+			return 0, fmt.Errorf("%s = %g: want a non-negative integer", name, v)
+		}
+		return n, nil
+	}
+	switch name {
+	case NSlots:
+		n, err := integer()
+		if err != nil {
+			return err
+		}
+		in.ScaleZones(n)
+		return nil
+	case G:
+		n, err := integer()
+		if err != nil {
+			return err
+		}
+		in.G = n
+		return nil
+	case K:
+		n, err := integer()
+		if err != nil {
+			return err
+		}
+		in.K = n
+		return in.Derive(eff, true)
+	case R:
+		for m := range in.R {
+			in.R[m] = in.R[m].Scale(v)
+		}
+		return in.Derive(eff, false)
+	}
+	m := strings.TrimSuffix(strings.TrimPrefix(name, "R["), "]")
+	if _, ok := in.R[m]; !ok {
+		return fmt.Errorf("%s: unknown modulation %q", name, m)
+	}
+	in.R[m] = units.Distance(v)
+	return in.Derive(eff, false)
+}
+
+// Point is the outcome at one parameter setting. Y is zero for sweeps of a
+// single parameter.
+type Point struct {
+	X, Y float64
+	solve.Result
+	Routable      int // requests with at least one reach-feasible route
+	Weight        float64
+	Fragmentation float64
+	Err           error
+}
+
+// Sweep solves in with engine at every value of x, or at every pair of
+// values of x and y when y is not nil. A failure at one point is recorded
+// in that point and does not stop the sweep.
+func Sweep(in *instance.Instance, engine string, opt solve.Options, x Param, y *Param) []Point {
+	eff := in.Efficiency()
+	ys := []float64{0}
+	if y != nil {
+		ys = y.Values
+	}
+	var pts []Point
+	for _, yv := range ys {
+		for _, xv := range x.Values {
+			pt := Point{X: xv, Y: yv}
+			c := in.Clone()
+			pt.Err = Apply(c, eff, x.Name, xv)
+			if pt.Err == nil && y != nil {
+				pt.Err = Apply(c, eff, y.Name, yv)
+			}
+			if pt.Err == nil {
+				pt.Routable = routable(c)
+				pt.Result, pt.Err = solve.Run(engine, c, opt)
+			}
+			if pt.Err == nil {
+				pt.Err = pt.Plan.Validate(c)
+				pt.Weight, pt.Fragmentation = pt.Plan.Weight(c), pt.Plan.Fragmentation(c)
+			}
+			pts = append(pts, pt)
+		}
+	}
+	return pts
+}
+
+func routable(in *instance.Instance) int {
+	n := 0
+	for _, t := range in.Traffic {
+		if len(plan.Candidates(in, t)) > 0 {
+			n++
+		}
+	}
+	return n
+}
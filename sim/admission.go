@@ -0,0 +1,181 @@
+package sim
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+)
+
+// Admission is trunk-reservation style admission control: a request of one
+// of Classes is admitted only if, after its allocation, every link of its
+// path still has a free contiguous run of at least Threshold[zone] slots in
+// the zone it was placed in. Requests of other classes are always admitted.
+type Admission struct {
+	Classes   map[string]bool
+	Threshold map[string]int // per zone name, in slots
+}
+
+// NewAdmission returns admission control for every class but the highest
+// priority one of in, with all thresholds zero.
+func NewAdmission(in *instance.Instance) *Admission {
+	a := &Admission{Classes: map[string]bool{}, Threshold: map[string]int{}}
+	if len(in.Classes) > 1 {
+		for _, c := range in.Classes[1:] {
+			a.Classes[c] = true
+		}
+	}
+	return a
+}
+
+// reserving marks slots held back while a placement is evaluated.
+const reserving = -1
+
+func (a *Admission) admits(n *Network, r *Request, pl Placement) bool {
+	if a == nil || !a.Classes[r.Class] {
+		return true
+	}
+	z := n.Zones[pl.Zone]
+	th := a.Threshold[z.Name]
+	if th <= 0 {
+		return true
+	}
+	links := n.Options[r.T][pl.Opt].Links
+	n.Grid.Assign(links, pl.Start, pl.Start+pl.Width, reserving)
+	defer n.Grid.Release(links, pl.Start, pl.Start+pl.Width)
+	for _, li := range links {
+		if n.Grid.LargestFree([]int{li}, z.Lo, z.Hi) < th {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseThresholds parses per-zone thresholds given as "zone=slots,...".
+func ParseThresholds(s string) (map[string]int, error) {
+	th := map[string]int{}
+	if s == "" {
+		return th, nil
+	}
+	for _, kv := range strings.Split(s, ",") {
+		z, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			return nil, fmt.Errorf("threshold %q: want zone=slots", kv)
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("threshold %q: %w", kv, err)
+		}
+		th[z] = n
+	}
+	return th, nil
+}
+
+// FormatThresholds is the inverse of ParseThresholds, listing zones in
+// the order of zones.
+func FormatThresholds(th map[string]int, zones []string) string {
+	parts := make([]string, 0, len(zones))
+	for _, z := range zones {
+		parts = append(parts, fmt.Sprintf("%s=%d", z, th[z]))
+	}
+	return strings.Join(parts, ",")
+}
+
+// WeightedLoss is the class-weight (W) average of the per-class loss
+// probabilities of st, the figure of merit of the threshold sweep.
+func WeightedLoss(in *instance.Instance, st *Stats) float64 {
+	var num, den float64
+	for _, c := range st.Classes {
+		w, ok := in.W[c]
+		if !ok {
+			w = 1
+		}
+		cs := st.Class(c)
+		num += w * float64(cs.Blocked+cs.Dropped)
+		den += w * float64(cs.Arrivals)
+	}
+	if den == 0 {
+		return 0
+	}
+	return num / den
+}
+
+// ZoneNames lists the zones policy allocates from on in: ZONES, unless the
+// policy lays out zones of its own.
+func ZoneNames(in *instance.Instance, policy string, cfg Config) ([]string, error) {
+	n := NewNetwork(in)
+	if _, err := NewAllocator(policy, n, cfg); err != nil {
+		return nil, err
+	}
+	names := make([]string, len(n.Zones))
+	for i, z := range n.Zones {
+		names[i] = z.Name
+	}
+	return names, nil
+}
+
+// SweepReservation tunes the per-zone thresholds of adm by coordinate
+// descent over values, minimising WeightedLoss. Every evaluation simulates
+// a fresh network with the same configuration and seed, so candidate
+// thresholds see identical traffic. Each evaluation is reported to log
+// when it is not nil. The best thresholds are stored in adm and returned
+// with their statistics.
+func SweepReservation(in *instance.Instance, policy string, cfg Config, adm *Admission, values []int, log io.Writer) (*Stats, error) {
+	zones, err := ZoneNames(in, policy, cfg)
+	if err != nil {
+		return nil, err
+	}
+	eval := func(th map[string]int) (*Stats, float64, error) {
+		a := *adm
+		a.Threshold = th
+		c := cfg
+		c.Admission = &a
+		n := NewNetwork(in)
+		alloc, err := NewAllocator(policy, n, c)
+		if err != nil {
+			return nil, 0, err
+		}
+		st := New(n, alloc, c).Run()
+		loss := WeightedLoss(in, st)
+		if log != nil {
+			fmt.Fprintf(log, "%s\t%.4e\n", FormatThresholds(th, zones), loss)
+		}
+		return st, loss, nil
+	}
+	copyTh := func(th map[string]int) map[string]int {
+		c := make(map[string]int, len(th))
+		for k, v := range th {
+			c[k] = v
+		}
+		return c
+	}
+
+	best := copyTh(adm.Threshold)
+	bestSt, bestLoss, err := eval(best)
+	if err != nil {
+		return nil, err
+	}
+	for improved := true; improved; {
+		improved = false
+		for _, z := range zones {
+			for _, v := range values {
+				if v == best[z] {
+					continue
+				}
+				th := copyTh(best)
+				th[z] = v
+				st, loss, err := eval(th)
+				if err != nil {
+					return nil, err
+				}
+				if loss < bestLoss {
+					best, bestSt, bestLoss, improved = th, st, loss, true
+				}
+			}
+		}
+	}
+	adm.Threshold = best
+	return bestSt, nil
+}
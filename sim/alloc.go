@@ -0,0 +1,106 @@
+package sim
+
+import "fmt"
+
+// Allocator chooses a placement for a request without modifying the network.
+type Allocator interface {
+	Name() string
+	Place(n *Network, r *Request) (Placement, bool)
+}
+
+// ZoneFLF is the zone first-last-fit policy of ilp.mod: requests needing at
+// most Split slots are packed from the left edge of a zone, larger ones from
+// the right edge, so the two connection types meet in the middle.
+type ZoneFLF struct {
+	Split int
+}
+
+// NewZoneFLF returns a zone-FLF allocator splitting connection types at the
+// mean N_req of the network's options.
+func NewZoneFLF(n *Network) *ZoneFLF {
+	sum, cnt := 0, 0
+	for _, opts := range n.Options {
+		for _, o := range opts {
+			sum += o.Slots
+			cnt++
+		}
+	}
+	split := 0
+	if cnt > 0 {
+		split = sum / cnt
+	}
+	return &ZoneFLF{Split: split}
+}
+
+func (a *ZoneFLF) Name() string { return "zone-flf" }
+
+// SideOf returns the zone edge used for a block of the given N_req.
+func (a *ZoneFLF) SideOf(slots int) Side {
+	if slots <= a.Split {
+		return Left
+	}
+	return Right
+}
+
+func (a *ZoneFLF) Place(n *Network, r *Request) (Placement, bool) {
+	for oi, o := range n.Options[r.T] {
+		w := n.Width(o)
+		side := a.SideOf(o.Slots)
+		for zi, z := range n.Zones {
+			var s int
+			if side == Left {
+				s = n.Grid.FirstFit(o.Links, w, z.Lo, z.Hi)
+			} else {
+				s = n.Grid.LastFit(o.Links, w, z.Lo, z.Hi)
+			}
+			if s >= 0 {
+				return Placement{oi, zi, side, s, w}, true
+			}
+		}
+	}
+	return Placement{}, false
+}
+
+// FirstFit ignores zones and takes the lowest free block of the whole
+// spectrum on the first option that has one.
+type FirstFit struct{}
+
+func (FirstFit) Name() string { return "first-fit" }
+
+func (FirstFit) Place(n *Network, r *Request) (Placement, bool) {
+	for oi, o := range n.Options[r.T] {
+		w := n.Width(o)
+		if s := n.Grid.FirstFit(o.Links, w, 0, n.Grid.Slots); s >= 0 {
+			return Placement{oi, n.zoneOf(s), Left, s, w}, true
+		}
+	}
+	return Placement{}, false
+}
+
+// zoneOf returns the zone containing slot s.
+func (n *Network) zoneOf(s int) int {
+	for zi, z := range n.Zones {
+		if s >= z.Lo && s < z.Hi {
+			return zi
+		}
+	}
+	return 0
+}
+
+// NewAllocator returns the allocator registered under name for a run of
+// cfg on n. For rate-zones it sets the layout as the zones of n.
+func NewAllocator(name string, n *Network, cfg Config) (Allocator, error) {
+	switch name {
+	case "zone-flf", "flf":
+		return NewZoneFLF(n), nil
+	case "first-fit", "ff":
+		return FirstFit{}, nil
+	case "time-aware", "ta":
+		return NewTimeAware(cfg.Holding), nil
+	case "rate-zones", "rz":
+		a := NewRateZones(n)
+		n.SetZones(a.Layout())
+		return a, nil
+	}
+	return nil, fmt.Errorf("unknown allocation policy %q", name)
+}
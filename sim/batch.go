@@ -0,0 +1,149 @@
+package sim
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dilwar-crnlab/hpsr_2025/milp"
+	"github.com/dilwar-crnlab/hpsr_2025/model"
+)
+
+// Batching provisions arrivals in batches instead of one by one: requests
+// arriving within a window of Window time units are buffered and, at the
+// end of the window, the batch is placed jointly into the spectrum left
+// free by the established lightpaths by solving
+//
+//	max sum W[class] x[r,o,s]
+//	sum over o,s of x[r,o,s] <= 1                          per request
+//	sum over x of the batch holding slot q of link l <= 1  per l, q
+//
+// with the milp engine, where x[r,o,s] places request r with option o at
+// start s of a block of N_req + G slots inside one zone and free on every
+// link of o. Requests left out are blocked. A request waits from its
+// arrival to the end of its window, its setup latency, and holds its
+// lightpath for its full holding time from then on. Crankback, admission
+// control and preemption do not apply to batches.
+//
+// The node limit keeps runs reproducible. A time limit stops the solver by
+// the wall clock, so the plans of a batch, and with them every later
+// event, depend on the machine and its load; it breaks common random
+// numbers and replications that must be repeatable, and is off by default.
+type Batching struct {
+	Window    float64
+	NodeLimit int           // branch-and-bound nodes per batch, <= 0 for no limit
+	TimeLimit time.Duration // wall clock per batch, <= 0 for no limit
+}
+
+// buffered is a request waiting for its batch.
+type buffered struct {
+	r        *Request
+	counting bool
+}
+
+type batchVar struct {
+	b  int // index into the batch
+	pl Placement
+}
+
+// provision places the buffered requests at the end of their window, now.
+// Without a solution within the limits it falls back to the allocator in
+// arrival order.
+func (s *Sim) provision() {
+	batch := s.buffer
+	s.buffer = nil
+	if len(batch) == 0 {
+		return
+	}
+	n := s.Net
+	m := model.NewMILP("batch")
+	var vars []batchVar
+	occupy := make([][][]model.Term, len(n.Grid.Links))
+	for i := range occupy {
+		occupy[i] = make([][]model.Term, n.Grid.Slots)
+	}
+	for bi, q := range batch {
+		r := q.r
+		var one []model.Term
+		w := n.In.Weight(n.In.Traffic[r.T])
+		for oi, o := range n.Options[r.T] {
+			width := n.Width(o)
+			for zi, z := range n.Zones {
+				for st := z.Lo; st+width <= z.Hi; st++ {
+					if !n.Grid.IsFree(o.Links, st, st+width) {
+						continue
+					}
+					v := m.AddVar(fmt.Sprintf("x(%d,%d,%d)", r.ID, oi, st), model.Binary, 0, 1)
+					// Ties go to fewer slot-links, then to lower starts.
+					m.Maximize(model.Term{Var: v, Coef: w * (1 - 1e-4*float64(width*len(o.Links))/float64(n.Grid.Slots*len(n.Grid.Links)) -
+						1e-6*float64(st)/float64(n.Grid.Slots))})
+					vars = append(vars, batchVar{bi, Placement{oi, zi, Left, st, width}})
+					one = append(one, model.Term{Var: v, Coef: 1})
+					for _, li := range o.Links {
+						for q := st; q < st+width; q++ {
+							occupy[li][q] = append(occupy[li][q], model.Term{Var: v, Coef: 1})
+						}
+					}
+				}
+			}
+		}
+		if len(one) > 1 {
+			m.AddRow(fmt.Sprintf("Assign(%d)", r.ID), one, model.LE, 1)
+		}
+	}
+	for li, row := range occupy {
+		for q, terms := range row {
+			if len(terms) > 1 {
+				m.AddRow(fmt.Sprintf("Capacity(%d,%d)", li, q), terms, model.LE, 1)
+			}
+		}
+	}
+	o := milp.Options{NodeLimit: s.Cfg.Batch.NodeLimit}
+	if s.Cfg.Batch.TimeLimit > 0 {
+		o.Deadline = time.Now().Add(s.Cfg.Batch.TimeLimit)
+	}
+	// Nothing fits anywhere when the batch has no variables.
+	res := milp.Result{Status: milp.Optimal, X: []float64{}}
+	if len(vars) > 0 {
+		res = milp.Solve(m, o)
+	}
+	placed := make([]*Placement, len(batch))
+	if res.X != nil {
+		for i, v := range vars {
+			if res.X[i] > 0.5 {
+				placed[v.b] = &vars[i].pl
+			}
+		}
+	} else {
+		// Place one by one on a scratch copy of the grid.
+		saved := n.Grid
+		n.Grid = saved.Clone()
+		for bi, q := range batch {
+			if pl, ok := s.Alloc.Place(n, q.r); ok {
+				n.Grid.Assign(n.Options[q.r.T][pl.Opt].Links, pl.Start, pl.Start+pl.Width, q.r.ID)
+				placed[bi] = &pl
+			}
+		}
+		n.Grid = saved
+	}
+	if batch[len(batch)-1].counting {
+		s.Stats.Batches++
+		if res.Status != milp.Optimal {
+			s.Stats.Unproven++
+		}
+	}
+	for bi, q := range batch {
+		r := q.r
+		if placed[bi] == nil {
+			if q.counting {
+				s.Stats.Class(r.Class).Blocked++
+				s.Stats.Demand(r.T).Blocked++
+			}
+			continue
+		}
+		r.Setup = s.now - r.Arrival
+		if q.counting {
+			s.Stats.sampleSetup(r.Setup)
+		}
+		s.establish(r, *placed[bi])
+	}
+}
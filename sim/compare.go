@@ -0,0 +1,181 @@
+package sim
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"text/tabwriter"
+
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+)
+
+// Estimate is a sample mean with the half-width of its 95% confidence
+// interval.
+type Estimate struct {
+	Mean, HalfWidth float64
+	N               int
+}
+
+func (e Estimate) String() string {
+	return fmt.Sprintf("%.3e ± %.1e", e.Mean, e.HalfWidth)
+}
+
+// Comparison is the outcome of Compare. Estimates are of WeightedLoss,
+// which is the blocking probability for instances without classes and
+// preemption.
+type Comparison struct {
+	Policies   []string
+	Antithetic bool
+	Loss       []Estimate // per policy
+	// Diff[i] is the paired difference of the loss of policy i and that of
+	// Policies[0] in the same replication.
+	Diff []Estimate
+	// Gain[i] is the variance of the difference had the replications of
+	// policy i and Policies[0] been independent, over that of the paired
+	// difference: the factor of replications that common random numbers
+	// save.
+	Gain []float64
+	// AntitheticGain[i] is the variance of the loss of one run over twice
+	// that of the mean of an antithetic pair: the factor of runs that
+	// antithetic pairing saves. It is only set with Antithetic.
+	AntitheticGain []float64
+}
+
+// Compare simulates every policy in reps replications with common random
+// numbers: replication r of every policy runs on a fresh network with seed
+// cfg.Seed+r, so the policies see identical traffic and their differences
+// are estimated from paired samples. With antithetic, each replication is
+// the mean of the run with its seed and of the antithetic run.
+func Compare(in *instance.Instance, policies []string, cfg Config, reps int, antithetic bool) (*Comparison, error) {
+	if reps < 2 {
+		return nil, fmt.Errorf("compare: need at least 2 replications, have %d", reps)
+	}
+	run := func(policy string, seed int64, anti bool) (float64, error) {
+		c := cfg
+		c.Seed, c.Antithetic = seed, anti
+		n := NewNetwork(in)
+		alloc, err := NewAllocator(policy, n, c)
+		if err != nil {
+			return 0, err
+		}
+		return WeightedLoss(in, New(n, alloc, c).Run()), nil
+	}
+	cmp := &Comparison{Policies: policies, Antithetic: antithetic}
+	loss := make([][]float64, len(policies)) // per policy and replication
+	for i, p := range policies {
+		var single []float64
+		for r := range reps {
+			seed := cfg.Seed + int64(r)
+			x, err := run(p, seed, false)
+			if err != nil {
+				return nil, err
+			}
+			single = append(single, x)
+			if antithetic {
+				y, err := run(p, seed, true)
+				if err != nil {
+					return nil, err
+				}
+				single = append(single, y)
+				x = (x + y) / 2
+			}
+			loss[i] = append(loss[i], x)
+		}
+		cmp.Loss = append(cmp.Loss, estimate(loss[i]))
+		if antithetic {
+			cmp.AntitheticGain = append(cmp.AntitheticGain, ratio(variance(single), 2*variance(loss[i])))
+		}
+	}
+	for i := range policies {
+		diff := make([]float64, reps)
+		for r := range diff {
+			diff[r] = loss[i][r] - loss[0][r]
+		}
+		cmp.Diff = append(cmp.Diff, estimate(diff))
+		cmp.Gain = append(cmp.Gain, ratio(variance(loss[i])+variance(loss[0]), variance(diff)))
+	}
+	return cmp, nil
+}
+
+// Write prints a table of c, one row per policy.
+func (c *Comparison) Write(w io.Writer) error {
+	runs := "runs"
+	if c.Antithetic {
+		runs = "antithetic pairs"
+	}
+	if _, err := fmt.Fprintf(w, "%d replications (%s) with common random numbers, differences against %s\n",
+		c.Loss[0].N, runs, c.Policies[0]); err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	head := "policy\tloss\tdifference\tCRN gain\t"
+	if c.Antithetic {
+		head += "antithetic gain\t"
+	}
+	fmt.Fprintln(tw, head)
+	for i, p := range c.Policies {
+		fmt.Fprintf(tw, "%s\t%v\t", p, c.Loss[i])
+		if i == 0 {
+			fmt.Fprint(tw, "-\t-\t")
+		} else {
+			fmt.Fprintf(tw, "%+.3e ± %.1e\t%.3g\t", c.Diff[i].Mean, c.Diff[i].HalfWidth, c.Gain[i])
+		}
+		if c.Antithetic {
+			fmt.Fprintf(tw, "%.3g\t", c.AntitheticGain[i])
+		}
+		fmt.Fprintln(tw)
+	}
+	return tw.Flush()
+}
+
+func estimate(xs []float64) Estimate {
+	e := Estimate{N: len(xs)}
+	for _, x := range xs {
+		e.Mean += x
+	}
+	e.Mean /= float64(len(xs))
+	if len(xs) > 1 {
+		e.HalfWidth = t95(len(xs)-1) * math.Sqrt(variance(xs)/float64(len(xs)))
+	}
+	return e
+}
+
+// variance is the sample variance of xs.
+func variance(xs []float64) float64 {
+	if len(xs) < 2 {
+		return 0
+	}
+	m := 0.0
+	for _, x := range xs {
+		m += x
+	}
+	m /= float64(len(xs))
+	v := 0.0
+	for _, x := range xs {
+		v += (x - m) * (x - m)
+	}
+	return v / float64(len(xs)-1)
+}
+
+// ratio is a/b, infinite when only b vanishes and 1 when both do.
+func ratio(a, b float64) float64 {
+	switch {
+	case b > 0:
+		return a / b
+	case a > 0:
+		return math.Inf(1)
+	}
+	return 1
+}
+
+// t95 is the two-sided 95% quantile of Student's t with df degrees of
+// freedom.
+func t95(df int) float64 {
+	table := []float64{12.706, 4.303, 3.182, 2.776, 2.571, 2.447, 2.365, 2.306, 2.262, 2.228,
+		2.201, 2.179, 2.160, 2.145, 2.131, 2.120, 2.110, 2.101, 2.093, 2.086,
+		2.080, 2.074, 2.069, 2.064, 2.060, 2.056, 2.052, 2.048, 2.045, 2.042}
+	if df <= len(table) {
+		return table[df-1]
+	}
+	return 1.96
+}
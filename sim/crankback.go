@@ -0,0 +1,253 @@
+package sim
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dilwar-crnlab/hpsr_2025/spectrum"
+)
+
+// Crankback steps. With crankback configured, the primary attempt offers
+// the allocator a narrow view of the request's options: its first option
+// (the first path in PATHS with its preferred modulation), in the first
+// zone, and there only in the half of the zone on the side of its
+// connection type, as zone-FLF defines it. Each configured step widens
+// the view before the next attempt, in the configured order:
+//
+//	path    the other candidate paths in PATHS, with their preferred modulation
+//	mod     every reach-feasible modulation of the paths allowed so far
+//	zone    every zone instead of the first one
+//	side    whole zones instead of the half on the side of the connection type
+//	split   two blocks of half the slots each on one of the allowed options
+//	defrag  compact the spectrum of the active lightpaths, then retry
+//
+// Steps left out are never taken, so a request may block although the
+// allocator alone would have placed it. The zone and side steps have no
+// effect on allocators that ignore the zones, such as first-fit.
+const (
+	StepPrimary = "primary"
+	StepPath    = "path"
+	StepMod     = "mod"
+	StepZone    = "zone"
+	StepSide    = "side"
+	StepSplit   = "split"
+	StepDefrag  = "defrag"
+)
+
+// ParseCrankback parses a comma separated list of crankback steps.
+func ParseCrankback(s string) ([]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var steps []string
+	seen := map[string]bool{}
+	for _, st := range strings.Split(s, ",") {
+		switch st {
+		case StepPath, StepMod, StepZone, StepSide, StepSplit, StepDefrag:
+		default:
+			return nil, fmt.Errorf("unknown crankback step %q", st)
+		}
+		if seen[st] {
+			return nil, fmt.Errorf("crankback step %q given twice", st)
+		}
+		seen[st] = true
+		steps = append(steps, st)
+	}
+	return steps, nil
+}
+
+// scope is the part of the search space open to an attempt.
+type scope struct {
+	paths, mods, zones, sides, split bool
+}
+
+// crankback places r with the primary attempt and then with the
+// configured steps, counting the one that succeeded. refused reports that
+// admission control turned down a placement along the way.
+func (s *Sim) crankback(r *Request) (ok, refused bool) {
+	var sc scope
+	if ok, refused = s.attempt(r, sc); ok {
+		s.Stats.hitStep(StepPrimary, s.counting)
+		return true, refused
+	}
+	for _, step := range s.Cfg.Crankback {
+		switch step {
+		case StepPath:
+			sc.paths = true
+		case StepMod:
+			sc.mods = true
+		case StepZone:
+			sc.zones = true
+		case StepSide:
+			sc.sides = true
+		case StepSplit:
+			sc.split = true
+		case StepDefrag:
+			s.defragment()
+		}
+		ok, no := s.attempt(r, sc)
+		refused = refused || no
+		if ok {
+			s.Stats.hitStep(step, s.counting)
+			return true, refused
+		}
+	}
+	return false, refused
+}
+
+// attempt establishes r on the first placement within sc that admission
+// control admits, either as one block or, when splitting is open and no
+// single block fits, as two.
+func (s *Sim) attempt(r *Request, sc scope) (ok, refused bool) {
+	n := s.Net
+	opts := s.scopeOptions(r, sc)
+	for _, oi := range opts {
+		pl, ok := s.placeIn(r, sc, oi, n.Options[r.T][oi].Slots)
+		if !ok {
+			continue
+		}
+		if s.Cfg.Admission.admits(n, r, pl) {
+			s.establish(r, pl)
+			return true, refused
+		}
+		refused = true
+	}
+	if !sc.split {
+		return false, refused
+	}
+	for _, oi := range opts {
+		half := (n.Options[r.T][oi].Slots + 1) / 2
+		first, ok := s.placeIn(r, sc, oi, half)
+		if !ok {
+			continue
+		}
+		if !s.Cfg.Admission.admits(n, r, first) {
+			refused = true
+			continue
+		}
+		n.Establish(r, first)
+		second, ok := s.placeIn(r, sc, oi, half)
+		admitted := ok && s.Cfg.Admission.admits(n, r, second)
+		n.Teardown(n.Active[r.ID])
+		if !admitted {
+			refused = refused || ok
+			continue
+		}
+		s.establish(r, first)
+		other := *r
+		other.ID = s.newID()
+		s.establish(&other, second)
+		return true, refused
+	}
+	return false, refused
+}
+
+// scopeOptions lists the option indices of r open in sc.
+func (s *Sim) scopeOptions(r *Request, sc scope) []int {
+	var idx []int
+	seen := map[string]bool{}
+	for oi, o := range s.Net.Options[r.T] {
+		if !sc.paths && o.Path != s.Net.Options[r.T][0].Path {
+			continue
+		}
+		if !sc.mods && seen[o.Path] {
+			continue
+		}
+		seen[o.Path] = true
+		idx = append(idx, oi)
+	}
+	return idx
+}
+
+// placeIn asks the allocator to place r with option oi, needing the given
+// N_req, on a view of the network that offers only that option and the
+// zones open in sc, and maps the placement back to the network.
+func (s *Sim) placeIn(r *Request, sc scope, oi, slots int) (Placement, bool) {
+	n := s.Net
+	o := n.Options[r.T][oi]
+	v := *n
+	v.Options = append([][]Option(nil), n.Options...)
+	narrowed := o
+	narrowed.Slots = slots
+	v.Options[r.T] = []Option{narrowed}
+	v.Zones = n.Zones[:1]
+	if sc.zones {
+		v.Zones = n.Zones
+	}
+	if !sc.sides {
+		side := s.types().SideOf(o.Slots)
+		halves := make([]spectrum.Zone, len(v.Zones))
+		for i, z := range v.Zones {
+			mid := (z.Lo + z.Hi) / 2
+			if side == Left {
+				z.Hi = mid
+			} else {
+				z.Lo = mid
+			}
+			halves[i] = z
+		}
+		v.Zones = halves
+	}
+	pl, ok := s.Alloc.Place(&v, r)
+	if !ok {
+		return Placement{}, false
+	}
+	pl.Opt, pl.Zone = oi, n.zoneOf(pl.Start)
+	return pl, true
+}
+
+// types returns the zone-FLF allocator that tells the connection types
+// apart: the allocator itself or one built for the network.
+func (s *Sim) types() *ZoneFLF {
+	if flf, ok := s.Alloc.(*ZoneFLF); ok {
+		return flf
+	}
+	if s.flf == nil {
+		s.flf = NewZoneFLF(s.Net)
+	}
+	return s.flf
+}
+
+// defragment re-packs every active lightpath towards the edge of its zone
+// it was allocated from, keeping its option and zone. Lightpaths nearest to
+// their edge move first, so each one can only slide towards the edge.
+func (s *Sim) defragment() {
+	n := s.Net
+	lps := make([]*Lightpath, 0, len(n.Active))
+	for _, lp := range n.Active {
+		lps = append(lps, lp)
+	}
+	dist := func(lp *Lightpath) int {
+		z := n.Zones[lp.Zone]
+		if lp.Side == Right {
+			return z.Hi - (lp.Start + lp.Width)
+		}
+		return lp.Start - z.Lo
+	}
+	sort.Slice(lps, func(a, b int) bool {
+		if da, db := dist(lps[a]), dist(lps[b]); da != db {
+			return da < db
+		}
+		return lps[a].ID < lps[b].ID
+	})
+	for _, lp := range lps {
+		z := n.Zones[lp.Zone]
+		links := n.Options[lp.T][lp.Opt].Links
+		n.Teardown(lp)
+		var st int
+		if lp.Side == Right {
+			st = n.Grid.LastFit(links, lp.Width, lp.Start, z.Hi)
+		} else {
+			st = n.Grid.FirstFit(links, lp.Width, z.Lo, lp.Start+lp.Width)
+		}
+		pl := lp.Placement
+		if st >= 0 {
+			pl.Start = st
+		}
+		n.Establish(lp.Request, pl)
+		if pl.Start != lp.Start {
+			s.Stats.moved(s.counting)
+		}
+	}
+}
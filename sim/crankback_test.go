@@ -0,0 +1,83 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+)
+
+func TestCrankback(t *testing.T) {
+	in, err := instance.Load("../testdata/crankback.dat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Options of (0,2): 0 is p1 m1, 1 is p1 m2, 2 is p2 m1, 3 is p2 m2.
+	// The primary attempt offers a 2-slot block of p1 in slots [0,4).
+	busy := func(n *Network, lo, hi int) {
+		n.Grid.Assign(n.Options[0][0].Links, lo, hi, 1000)
+	}
+	tests := []struct {
+		step  string
+		setup func(n *Network)
+		opt   int
+		start int
+		parts int
+	}{
+		{StepPrimary, func(n *Network) {}, 0, 0, 1},
+		{StepPath, func(n *Network) { busy(n, 0, 16) }, 2, 0, 1},
+		{StepMod, func(n *Network) { busy(n, 0, 4) }, 1, 4, 1},
+		{StepZone, func(n *Network) { busy(n, 0, 4) }, 0, 8, 1},
+		{StepSide, func(n *Network) { busy(n, 0, 4) }, 0, 4, 1},
+		{StepSplit, func(n *Network) { busy(n, 0, 1); busy(n, 2, 3) }, 0, 1, 2},
+		{StepDefrag, func(n *Network) {
+			n.Establish(&Request{ID: 500}, Placement{Opt: 0, Zone: 0, Side: Left, Start: 1, Width: 2})
+		}, 0, 2, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.step, func(t *testing.T) {
+			cfg := Config{Load: 1, Holding: 1, Crankback: []string{tt.step}}
+			if tt.step == StepPrimary {
+				cfg.Crankback = []string{StepDefrag}
+			}
+			n := NewNetwork(in)
+			s := New(n, NewZoneFLF(n), cfg)
+			s.counting = true
+			tt.setup(n)
+			before := len(n.Active)
+			r := &Request{ID: s.newID(), T: 0}
+			if ok, _ := s.crankback(r); !ok {
+				t.Fatal("not placed")
+			}
+			for _, st := range s.Stats.Steps {
+				want := 0
+				if st == tt.step {
+					want = 1
+				}
+				if got := s.Stats.StepHits(st); got != want {
+					t.Errorf("%d hits at step %s, want %d", got, st, want)
+				}
+			}
+			if got := len(n.Active) - before; got != tt.parts {
+				t.Errorf("%d lightpaths established, want %d", got, tt.parts)
+			}
+			lp := n.Active[r.ID]
+			if lp.Opt != tt.opt || lp.Start != tt.start {
+				t.Errorf("option %d at slot %d, want option %d at slot %d", lp.Opt, lp.Start, tt.opt, tt.start)
+			}
+		})
+	}
+}
+
+func TestCrankbackNarrow(t *testing.T) {
+	in, err := instance.Load("../testdata/crankback.dat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Without steps that open them, the rest of the spectrum stays closed.
+	n := NewNetwork(in)
+	s := New(n, NewZoneFLF(n), Config{Load: 1, Holding: 1, Crankback: []string{StepSplit}})
+	n.Grid.Assign(n.Options[0][0].Links, 0, 4, 1000)
+	if ok, _ := s.crankback(&Request{ID: s.newID(), T: 0}); ok {
+		t.Errorf("placed outside the narrow view")
+	}
+}
@@ -0,0 +1,259 @@
+package sim
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"text/tabwriter"
+
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+	"github.com/dilwar-crnlab/hpsr_2025/spectrum"
+)
+
+// FixedPoint approximates the blocking probability of every demand under
+// an allocation policy with the reduced-load Erlang fixed point instead of
+// simulating. Every link is split into independent pools of slots, the
+// zones of the policy: ZONES for zone-flf and time-aware, the whole
+// spectrum for first-fit and the bandwidth-class zones for rate-zones. A
+// pool of C slots on one link is a multi-rate Erlang loss system whose
+// blocking for blocks of w = N_req + G slots follows from the
+// Kaufman-Roberts recursion, which ignores that a block must be contiguous
+// and aligned on all links of its path.
+//
+// A request tries its options and their pools in the order of the policy.
+// An attempt succeeds when no link of its path blocks width w in the pool,
+// links blocking independently, and fails independently of the attempts
+// before it. Each link of an attempt is offered the load of the demand
+// that overflowed the earlier attempts, thinned by the other links of the
+// path. The link blockings are iterated, damped by averaging with the
+// previous iterate, until no blocking changes by more than Tolerance.
+type FixedPoint struct {
+	Tolerance float64
+	MaxIter   int
+}
+
+// DefaultFixedPoint returns a fixed point of tolerance 1e-9 and at most
+// 1000 iterations.
+func DefaultFixedPoint() FixedPoint {
+	return FixedPoint{Tolerance: 1e-9, MaxIter: 1000}
+}
+
+// Approximation is the outcome of FixedPoint.Solve.
+type Approximation struct {
+	Policy     string
+	Load       float64
+	Demands    []instance.Demand
+	Offered    []float64 // Erlang per traffic index
+	Blocking   []float64 // per traffic index
+	Total      float64   // blocking of an arbitrary arrival
+	Iterations int
+	Converged  bool
+
+	// Simulated blocking per traffic index and in total, set by Simulate.
+	Sim      []Estimate
+	SimTotal Estimate
+}
+
+// attempt is one try of a demand: an option in one pool.
+type attempt struct {
+	links []int
+	pool  int
+	width int
+}
+
+// Solve approximates the blocking of policy on n at the given offered
+// load, which is shared among the demands in proportion to T_sd as in
+// the simulator.
+func (fp FixedPoint) Solve(n *Network, policy string, load float64) (*Approximation, error) {
+	alloc, err := NewAllocator(policy, n, Config{Load: load, Holding: 1})
+	if err != nil {
+		return nil, err
+	}
+	pools, tries, err := poolsOf(n, alloc)
+	if err != nil {
+		return nil, err
+	}
+	a := &Approximation{Policy: alloc.Name(), Load: load, Demands: n.In.Traffic}
+	var sum float64
+	for _, t := range n.In.Traffic {
+		sum += float64(n.In.T[t])
+	}
+	attempts := make([][]attempt, len(n.Options))
+	widest := 0
+	for ti, opts := range n.Options {
+		off := 0.0
+		if sum > 0 {
+			off = load * float64(n.In.T[n.In.Traffic[ti]]) / sum
+		}
+		a.Offered = append(a.Offered, off)
+		for _, o := range opts {
+			for _, p := range tries(o) {
+				attempts[ti] = append(attempts[ti], attempt{o.Links, p, n.Width(o)})
+			}
+			widest = max(widest, n.Width(o))
+		}
+	}
+
+	// Pool p of grid row li is resource li*len(pools)+p.
+	res := func(li, p int) int { return li*len(pools) + p }
+	block := make([][]float64, len(n.Grid.Links)*len(pools)) // by width
+	offered := make([][]float64, len(block))                 // by width
+	for i := range block {
+		block[i] = make([]float64, widest+1)
+		offered[i] = make([]float64, widest+1)
+	}
+	// accept is the probability that every link of at but skip has a
+	// free block.
+	accept := func(at attempt, skip int) float64 {
+		p := 1.0
+		for _, li := range at.links {
+			if li != skip {
+				p *= 1 - block[res(li, at.pool)][at.width]
+			}
+		}
+		return p
+	}
+	a.Blocking = make([]float64, len(n.Options))
+	for a.Iterations < fp.MaxIter {
+		a.Iterations++
+		for _, o := range offered {
+			clear(o)
+		}
+		for ti, ats := range attempts {
+			miss := 1.0 // probability that the attempts so far failed
+			for _, at := range ats {
+				for _, li := range at.links {
+					offered[res(li, at.pool)][at.width] += a.Offered[ti] * miss * accept(at, li)
+				}
+				miss *= 1 - accept(at, -1)
+			}
+			a.Blocking[ti] = miss
+		}
+		change := 0.0
+		for ri := range block {
+			z := pools[ri%len(pools)]
+			for w, b := range kaufmanRoberts(z.Hi-z.Lo, offered[ri]) {
+				b = (block[ri][w] + b) / 2
+				change = max(change, math.Abs(b-block[ri][w]))
+				block[ri][w] = b
+			}
+		}
+		if change < fp.Tolerance {
+			a.Converged = true
+			break
+		}
+	}
+	var off, lost float64
+	for ti, x := range a.Offered {
+		off += x
+		lost += x * a.Blocking[ti]
+	}
+	if off > 0 {
+		a.Total = lost / off
+	}
+	return a, nil
+}
+
+// poolsOf returns the pools of alloc and the pools, in the order tried,
+// of an option.
+func poolsOf(n *Network, alloc Allocator) ([]spectrum.Zone, func(Option) []int, error) {
+	switch a := alloc.(type) {
+	case *ZoneFLF, *TimeAware:
+		all := make([]int, len(n.Zones))
+		for i := range all {
+			all[i] = i
+		}
+		return n.Zones, func(Option) []int { return all }, nil
+	case FirstFit:
+		whole := []spectrum.Zone{{Name: "all", Lo: 0, Hi: n.Grid.Slots}}
+		return whole, func(Option) []int { return []int{0} }, nil
+	case *RateZones:
+		index := map[int]int{}
+		for i, r := range a.rates() {
+			index[r] = i
+		}
+		return a.Layout(), func(o Option) []int {
+			if p, ok := index[o.Slots]; ok {
+				return []int{p}
+			}
+			return nil
+		}, nil
+	}
+	return nil, nil, fmt.Errorf("fixed point: policy %s is not supported", alloc.Name())
+}
+
+// kaufmanRoberts returns the blocking, by width, of a pool of c slots
+// offered load[w] Erlang of requests for w slots each.
+func kaufmanRoberts(c int, load []float64) []float64 {
+	q := make([]float64, c+1) // unnormalised occupancy distribution
+	q[0] = 1
+	for j := 1; j <= c; j++ {
+		for w := 1; w <= j && w < len(load); w++ {
+			q[j] += load[w] * float64(w) * q[j-w]
+		}
+		q[j] /= float64(j)
+		if q[j] > 1e250 {
+			s := q[j]
+			for i := range q[:j+1] {
+				q[i] /= s
+			}
+		}
+	}
+	var sum float64
+	for _, x := range q {
+		sum += x
+	}
+	bl := make([]float64, len(load))
+	for w := range bl {
+		if w > c {
+			bl[w] = 1
+			continue
+		}
+		for j := c - w + 1; j <= c; j++ {
+			bl[w] += q[j]
+		}
+		bl[w] /= sum
+	}
+	return bl
+}
+
+// Simulate estimates the blocking of every demand of a by simulating its
+// policy on in at its load with DemandBlocking.
+func (a *Approximation) Simulate(in *instance.Instance, cfg Config, reps int) error {
+	cfg.Load = a.Load
+	var err error
+	a.Sim, a.SimTotal, err = DemandBlocking(in, a.Policy, cfg, reps)
+	return err
+}
+
+// Write prints the approximate blocking of every demand of a, next to the
+// simulated one when Simulate was called.
+func (a *Approximation) Write(w io.Writer) error {
+	status := "converged"
+	if !a.Converged {
+		status = "not converged"
+	}
+	if _, err := fmt.Fprintf(w, "policy %s, reduced-load fixed point at %g Erlang, %d iterations (%s)\n",
+		a.Policy, a.Load, a.Iterations, status); err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	head := "demand\toffered\tfixed point\t"
+	if a.Sim != nil {
+		head += "simulated\t"
+	}
+	fmt.Fprintln(tw, head)
+	for ti, t := range a.Demands {
+		fmt.Fprintf(tw, "%v\t%.4g\t%.3e\t", t, a.Offered[ti], a.Blocking[ti])
+		if a.Sim != nil {
+			fmt.Fprintf(tw, "%v\t", a.Sim[ti])
+		}
+		fmt.Fprintln(tw)
+	}
+	fmt.Fprintf(tw, "total\t%.4g\t%.3e\t", a.Load, a.Total)
+	if a.Sim != nil {
+		fmt.Fprintf(tw, "%v\t", a.SimTotal)
+	}
+	fmt.Fprintln(tw)
+	return tw.Flush()
+}
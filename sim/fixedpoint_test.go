@@ -0,0 +1,41 @@
+package sim
+
+import (
+	"math"
+	"testing"
+)
+
+func TestKaufmanRoberts(t *testing.T) {
+	tests := []struct {
+		name  string
+		c     int
+		load  []float64 // Erlang by width
+		width int
+		want  float64
+	}{
+		// With one width of one slot the recursion is Erlang B.
+		{"E(1,1)", 1, []float64{0, 1}, 1, 0.5},
+		{"E(2,1)", 2, []float64{0, 1}, 1, 0.2},
+		{"E(5,3)", 5, []float64{0, 3}, 1, 0.110054347826087},
+		{"E(10,5)", 10, []float64{0, 5}, 1, 0.018384570336648},
+		{"E(100,80)", 100, []float64{0, 80}, 1, 0.003992028604553},
+		// Blocks of w slots alone see Erlang B with floor(c/w) servers.
+		{"width 2 in 4", 4, []float64{0, 0, 1}, 2, 0.2},
+		{"width 2 in 5", 5, []float64{0, 0, 1}, 2, 0.2},
+		{"width 3 in 15", 15, []float64{0, 0, 0, 3}, 3, 0.110054347826087},
+		// Wider than the pool.
+		{"width 3 in 2", 2, []float64{0, 1, 0, 1}, 3, 1},
+		// Two widths in two slots: states 0, 1 (one narrow), 2 (two
+		// narrow or one wide) with weights 1, a1, a1^2/2 + a2.
+		{"narrow of two", 2, []float64{0, 1, 1}, 1, 1.5 / 3.5},
+		{"wide of two", 2, []float64{0, 1, 1}, 2, 2.5 / 3.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := kaufmanRoberts(tt.c, tt.load)[tt.width]
+			if math.Abs(got-tt.want) > 1e-9*tt.want {
+				t.Errorf("blocking %.9g, want %.9g", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+// Package sim is an event-driven simulator of dynamic lightpath
+// provisioning on the instance of ilp.mod: requests for the (s,d) pairs of
+// TRAFFIC arrive as a Poisson process, are routed over their candidate
+// PATHS with a reach-feasible modulation and receive a contiguous block of
+// N_req + G slots inside one zone.
+package sim
+
+import (
+	"sort"
+
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+	"github.com/dilwar-crnlab/hpsr_2025/spectrum"
+	"github.com/dilwar-crnlab/hpsr_2025/units"
+)
+
+// Option is a reach-feasible (path, modulation) choice of a demand.
+type Option struct {
+	Path  string
+	Mod   string
+	Links []int // grid rows of PATH_LINKS
+	Slots int   // N_req
+	Dist  units.Distance
+}
+
+// Side tells from which edge of a zone a block is taken.
+type Side int
+
+const (
+	Left  Side = iota // first fit from the low edge of the zone
+	Right             // last fit from the high edge of the zone
+)
+
+func (s Side) String() string {
+	if s == Right {
+		return "right"
+	}
+	return "left"
+}
+
+// Placement is a candidate spectrum assignment for a request.
+type Placement struct {
+	Opt   int // index into Network.Options[t]
+	Zone  int
+	Side  Side
+	Start int
+	Width int // N_req + G
+}
+
+// Request is a connection request of a demand.
+type Request struct {
+	ID      int
+	T       int // index into Instance.Traffic
+	Class   string
+	Arrival float64
+	Setup   float64 // delay from arrival to establishment
+	Holding float64
+}
+
+// Departure is the time at which the request's lightpath is torn down.
+func (r *Request) Departure() float64 { return r.Arrival + r.Setup + r.Holding }
+
+// Lightpath is an established request.
+type Lightpath struct {
+	*Request
+	Placement
+}
+
+// Network is the simulated network state.
+type Network struct {
+	In      *instance.Instance
+	Grid    *spectrum.Grid
+	Zones   []spectrum.Zone
+	Options [][]Option // per traffic index
+	Active  map[int]*Lightpath
+}
+
+// NewNetwork builds an empty network for in.
+func NewNetwork(in *instance.Instance) *Network {
+	n := &Network{
+		In:     in,
+		Grid:   spectrum.NewGrid(in),
+		Zones:  spectrum.Zones(in),
+		Active: map[int]*Lightpath{},
+	}
+	n.Options = make([][]Option, len(in.Traffic))
+	for ti, t := range in.Traffic {
+		n.Options[ti] = options(in, n.Grid, t)
+	}
+	return n
+}
+
+// SetZones replaces the zones of n by zs. Placements refer to zones by
+// index, so it must be called before any lightpath is established.
+func (n *Network) SetZones(zs []spectrum.Zone) {
+	n.Zones = zs
+}
+
+// options lists the candidate paths of t in PATHS order, each with its
+// reach-feasible modulations from the fewest required slots upwards.
+func options(in *instance.Instance, g *spectrum.Grid, t instance.Demand) []Option {
+	var opts []Option
+	for _, p := range in.Paths[t] {
+		k := instance.PathKey{T: t, P: p}
+		dist := in.PathDist(t, p)
+		links := g.Indices(in.PathLinks[k])
+		var po []Option
+		for _, m := range in.FeasMod[k] {
+			if dist > in.R[m] {
+				continue
+			}
+			po = append(po, Option{p, m, links, in.NReq[instance.ModKey{T: t, P: p, M: m}], dist})
+		}
+		sort.SliceStable(po, func(a, b int) bool { return po[a].Slots < po[b].Slots })
+		opts = append(opts, po...)
+	}
+	return opts
+}
+
+// Width is the number of slots a request occupies with option o.
+func (n *Network) Width(o Option) int { return o.Slots + n.In.G }
+
+// Establish commits pl for r.
+func (n *Network) Establish(r *Request, pl Placement) *Lightpath {
+	lp := &Lightpath{r, pl}
+	n.Grid.Assign(n.Options[r.T][pl.Opt].Links, pl.Start, pl.Start+pl.Width, r.ID)
+	n.Active[r.ID] = lp
+	return lp
+}
+
+// Teardown releases the spectrum of lp.
+func (n *Network) Teardown(lp *Lightpath) {
+	n.Grid.Release(n.Options[lp.T][lp.Opt].Links, lp.Start, lp.Start+lp.Width)
+	delete(n.Active, lp.ID)
+}
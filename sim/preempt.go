@@ -0,0 +1,90 @@
+package sim
+
+import (
+	"sort"
+
+	"github.com/dilwar-crnlab/hpsr_2025/spectrum"
+)
+
+// Preemption lets arrivals of class By that find no free block take the
+// spectrum of established lightpaths of class Victim. Preempted lightpaths
+// are offered to the allocator again for their remaining holding time and
+// dropped if that fails.
+type Preemption struct {
+	By, Victim string
+}
+
+func (p *Preemption) allows(class string) bool {
+	return p != nil && p.By == class
+}
+
+// preempt establishes r on the window that displaces the fewest victim
+// lightpaths, then tries to re-allocate the displaced ones.
+func (s *Sim) preempt(r *Request) bool {
+	pl, victims, ok := s.victimWindow(r)
+	if !ok {
+		return false
+	}
+	for _, lp := range victims {
+		s.Net.Teardown(lp)
+		s.count(lp.Request, func(c *ClassStats) { c.Preempted++ })
+	}
+	s.establish(r, pl)
+	s.count(r, func(c *ClassStats) { c.Preempting++ })
+	for _, lp := range victims {
+		left := *lp.Request
+		left.Holding = lp.Departure() - s.now
+		left.Arrival = s.now
+		if rp, ok := s.Alloc.Place(s.Net, &left); ok {
+			// The pending departure of lp.ID stays valid.
+			s.Net.Establish(lp.Request, rp)
+			s.count(lp.Request, func(c *ClassStats) { c.Reallocated++ })
+		} else {
+			s.count(lp.Request, func(c *ClassStats) { c.Dropped++ })
+		}
+	}
+	return true
+}
+
+// victimWindow scans every option and zone of r for the block whose slots
+// are all free or held by victim-class lightpaths, minimising the number of
+// lightpaths to preempt.
+func (s *Sim) victimWindow(r *Request) (Placement, []*Lightpath, bool) {
+	n := s.Net
+	best, bestCost := Placement{}, -1
+	var bestIDs map[int]bool
+	for oi, o := range n.Options[r.T] {
+		w := n.Width(o)
+		for zi, z := range n.Zones {
+		window:
+			for start := z.Lo; start+w <= z.Hi; start++ {
+				ids := map[int]bool{}
+				for _, li := range o.Links {
+					for sl := start; sl < start+w; sl++ {
+						id := n.Grid.Owner(li, sl)
+						if id == spectrum.Free || ids[id] {
+							continue
+						}
+						if n.Active[id].Class != s.Cfg.Preempt.Victim {
+							continue window
+						}
+						ids[id] = true
+						if bestCost >= 0 && len(ids) >= bestCost {
+							continue window
+						}
+					}
+				}
+				best, bestCost, bestIDs = Placement{oi, zi, Left, start, w}, len(ids), ids
+			}
+		}
+	}
+	if bestCost < 0 {
+		return Placement{}, nil, false
+	}
+	victims := make([]*Lightpath, 0, len(bestIDs))
+	for id := range bestIDs {
+		victims = append(victims, n.Active[id])
+	}
+	sort.Slice(victims, func(a, b int) bool { return victims[a].ID < victims[b].ID })
+	return best, victims, true
+}
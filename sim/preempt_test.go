@@ -0,0 +1,61 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+)
+
+func TestPreempt(t *testing.T) {
+	in, err := instance.Load("../testdata/path3.dat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Demand 0 is (0,1) of class a with 3 slots, 1 is (0,2) of class b
+	// with 4 and 2 is (1,2) of class c with 6; class a may preempt b.
+	n := NewNetwork(in)
+	s := New(n, FirstFit{}, Config{Load: 1, Holding: 1, Preempt: &Preemption{By: "a", Victim: "b"}})
+	s.counting = true
+	arrive := func(d int) *Request {
+		r := &Request{ID: s.newID(), T: d, Holding: 1}
+		r.Class = ClassOf(in, d)
+		s.arrive(r)
+		return r
+	}
+	b1, b2 := arrive(1), arrive(1) // [0,4) and [4,8)
+	arrive(2)                      // blocked: c may not preempt
+	a1 := arrive(0)                // preempts b1 alone at [0,3), b1 finds no room
+	arrive(1)                      // blocked: b may not preempt
+	a2 := arrive(0)                // preempts b2 at [3,6), b2 moves to [6,10)
+
+	for _, c := range []struct {
+		r     *Request
+		start int
+	}{{a1, 0}, {a2, 3}, {b2, 6}} {
+		lp, ok := n.Active[c.r.ID]
+		if !ok {
+			t.Errorf("request %d not active", c.r.ID)
+		} else if lp.Start != c.start {
+			t.Errorf("request %d at slot %d, want %d", c.r.ID, lp.Start, c.start)
+		}
+	}
+	if _, ok := n.Active[b1.ID]; ok {
+		t.Errorf("request %d still active", b1.ID)
+	}
+
+	want := map[string]ClassStats{
+		"a": {Arrivals: 2, Preempting: 2},
+		"b": {Arrivals: 3, Blocked: 1, Preempted: 2, Reallocated: 1, Dropped: 1},
+		"c": {Arrivals: 1, Blocked: 1},
+	}
+	blocking := map[string]float64{"a": 0, "b": 1.0 / 3, "c": 1}
+	for c, w := range want {
+		got := s.Stats.Class(c)
+		if *got != w {
+			t.Errorf("class %s: %+v, want %+v", c, *got, w)
+		}
+		if got.Blocking() != blocking[c] {
+			t.Errorf("class %s blocking %g, want %g", c, got.Blocking(), blocking[c])
+		}
+	}
+}
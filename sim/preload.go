@@ -0,0 +1,42 @@
+package sim
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/dilwar-crnlab/hpsr_2025/plan"
+)
+
+// Preload establishes the assignments of a static plan as permanent
+// lightpaths before the run, so that the dynamic traffic is served from
+// the state the plan leaves. Blocks hold their guard band up to N_slots,
+// as in plan.Plan.Grid.
+func (s *Sim) Preload(p plan.Plan) error {
+	n := s.Net
+	index := map[string]int{}
+	for ti, t := range n.In.Traffic {
+		index[t.String()] = ti
+	}
+	for _, a := range p {
+		ti, ok := index[a.T.String()]
+		if !ok {
+			return fmt.Errorf("preload: %v not in TRAFFIC", a.T)
+		}
+		oi := -1
+		for i, o := range n.Options[ti] {
+			if o.Path == a.P && o.Mod == a.M {
+				oi = i
+			}
+		}
+		if oi < 0 {
+			return fmt.Errorf("preload: %v has no option %s/%s", a.T, a.P, a.M)
+		}
+		w := min(a.Slots+n.In.G, n.In.NSlots-a.Start)
+		if !n.Grid.IsFree(n.Options[ti][oi].Links, a.Start, a.Start+w) {
+			return fmt.Errorf("preload: block of %v at %d is not free", a.T, a.Start)
+		}
+		r := &Request{ID: s.newID(), T: ti, Class: ClassOf(n.In, ti), Holding: math.Inf(1)}
+		n.Establish(r, Placement{oi, n.zoneOf(a.Start), Left, a.Start, w})
+	}
+	return nil
+}
@@ -0,0 +1,242 @@
+package sim
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"text/tabwriter"
+
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+)
+
+// Splitting estimates blocking probabilities too small for direct
+// simulation with RESTART, multilevel splitting for steady-state rare
+// events. The importance function is the number of occupied slots on the
+// busiest link, and Thresholds divide its range into regions 0 (below the
+// first threshold) to len(Thresholds). Whenever a trajectory crosses a
+// threshold upwards it is split into Factor copies: itself and Factor-1
+// retrials that continue with independent traffic until they fall back
+// below that threshold or the horizon ends. Every state in region k is
+// thereby visited Factor^k times in expectation, so each arrival seen in
+// region k counts with weight Factor^-k, and the weighted fraction of
+// blocked arrivals estimates the blocking probability at a cost that
+// grows with the number of thresholds rather than with 1/blocking.
+//
+// The main trajectory of a replication runs Warmup arrivals unsplit, then
+// for the expected duration of Arrivals arrivals. The 95% confidence
+// interval comes from independent replications with consecutive seeds.
+type Splitting struct {
+	Thresholds   []int // increasing, in occupied slots
+	Factor       int   // copies per crossing, >= 2
+	Replications int   // >= 2
+}
+
+// RareResult is the outcome of Rare.
+type RareResult struct {
+	Blocking Estimate
+	// Retrials counts the trajectories split off the main ones and
+	// Simulated the arrivals simulated by all trajectories, the cost of
+	// the estimate.
+	Retrials, Simulated int
+	// Visits is the number of arrivals seen in each region, summed over
+	// all trajectories.
+	Visits []int
+}
+
+// restart is the state of one RESTART replication.
+type restart struct {
+	sp     Splitting
+	end    float64 // horizon of every trajectory
+	seeds  *rand.Rand
+	weight []float64 // per region
+
+	arrivals, blocked float64 // weighted
+	res               *RareResult
+}
+
+// Rare estimates the blocking probability of policy on in by splitting.
+// Batching is not supported, as the fate of a buffered arrival is only
+// known at the end of its window.
+func Rare(in *instance.Instance, policy string, cfg Config, sp Splitting) (*RareResult, error) {
+	switch {
+	case cfg.Batch != nil:
+		return nil, errors.New("rare: splitting does not support batching")
+	case sp.Factor < 2:
+		return nil, fmt.Errorf("rare: splitting factor %d, want at least 2", sp.Factor)
+	case sp.Replications < 2:
+		return nil, fmt.Errorf("rare: need at least 2 replications, have %d", sp.Replications)
+	case len(sp.Thresholds) == 0:
+		return nil, errors.New("rare: no thresholds")
+	}
+	for i := 1; i < len(sp.Thresholds); i++ {
+		if sp.Thresholds[i] <= sp.Thresholds[i-1] {
+			return nil, fmt.Errorf("rare: thresholds %v are not increasing", sp.Thresholds)
+		}
+	}
+	res := &RareResult{Visits: make([]int, len(sp.Thresholds)+1)}
+	weight := make([]float64, len(sp.Thresholds)+1)
+	for k := range weight {
+		weight[k] = math.Pow(float64(sp.Factor), -float64(k))
+	}
+	rate := cfg.Load / cfg.Holding
+	var bs []float64
+	for r := range sp.Replications {
+		c := cfg
+		c.Seed += int64(r)
+		n := NewNetwork(in)
+		alloc, err := NewAllocator(policy, n, c)
+		if err != nil {
+			return nil, err
+		}
+		s := New(n, alloc, c)
+		for range c.Warmup {
+			s.now += s.traffic.exp(1 / rate)
+			s.departUntil(s.now)
+			s.arrive(s.request())
+		}
+		res.Simulated += c.Warmup
+		s.counting = true
+		x := &restart{sp: sp, end: s.now + float64(c.Arrivals)/rate, seeds: rand.New(rand.NewSource(c.Seed)), weight: weight, res: res}
+		x.run(s, 0, x.region(s))
+		if x.arrivals > 0 {
+			bs = append(bs, x.blocked/x.arrivals)
+		}
+	}
+	res.Blocking = estimate(bs)
+	return res, nil
+}
+
+// region is the region of the importance function s is in.
+func (x *restart) region(s *Sim) int {
+	f, k := s.Net.Grid.MaxUsed(), 0
+	for k < len(x.sp.Thresholds) && f >= x.sp.Thresholds[k] {
+		k++
+	}
+	return k
+}
+
+// run advances trajectory s from region cur until it falls below region
+// kill or reaches the horizon, recursively running the retrials it splits
+// off.
+func (x *restart) run(s *Sim, kill, cur int) {
+	for {
+		for k := x.region(s); cur < k; {
+			cur++
+			for range x.sp.Factor - 1 {
+				x.res.Retrials++
+				x.run(s.clone(x.seeds.Int63()), cur, cur)
+			}
+		}
+		at := s.now + s.traffic.exp(s.Cfg.Holding/s.Cfg.Load)
+		if at > x.end {
+			return
+		}
+		s.now = at
+		s.departUntil(at)
+		if cur = x.region(s); cur < kill {
+			return
+		}
+		r := s.request()
+		c := s.Stats.Class(r.Class)
+		before := c.Blocked
+		s.arrive(r)
+		x.res.Simulated++
+		x.res.Visits[cur]++
+		x.arrivals += x.weight[cur]
+		if c.Blocked > before {
+			x.blocked += x.weight[cur]
+		}
+	}
+}
+
+// clone returns a copy of s that continues with its own traffic stream.
+func (s *Sim) clone(seed int64) *Sim {
+	c := *s
+	c.Net = s.Net.clone()
+	c.Stats = NewStats(s.Stats.Policy, s.Net.In)
+	c.traffic = newStream(seed, false)
+	c.pending = append(departures(nil), s.pending...)
+	return &c
+}
+
+// clone returns a copy of n with its own spectrum and lightpaths.
+func (n *Network) clone() *Network {
+	c := *n
+	c.Grid = n.Grid.Clone()
+	c.Active = make(map[int]*Lightpath, len(n.Active))
+	for id, lp := range n.Active {
+		cp := *lp
+		c.Active[id] = &cp
+	}
+	return &c
+}
+
+// Direct estimates the blocking probability of policy on in by plain
+// simulation, from reps independent runs with consecutive seeds, for
+// validating Rare where direct simulation still sees blocking.
+func Direct(in *instance.Instance, policy string, cfg Config, reps int) (Estimate, error) {
+	var bs []float64
+	for r := range reps {
+		c := cfg
+		c.Seed += int64(r)
+		n := NewNetwork(in)
+		alloc, err := NewAllocator(policy, n, c)
+		if err != nil {
+			return Estimate{}, err
+		}
+		t := New(n, alloc, c).Run().Total()
+		bs = append(bs, t.Blocking())
+	}
+	return estimate(bs), nil
+}
+
+// DemandBlocking estimates the blocking probability of every demand of in
+// under policy, and that of all arrivals, by plain simulation from reps
+// independent runs with consecutive seeds.
+func DemandBlocking(in *instance.Instance, policy string, cfg Config, reps int) ([]Estimate, Estimate, error) {
+	if reps < 2 {
+		return nil, Estimate{}, fmt.Errorf("need at least 2 replications, have %d", reps)
+	}
+	perDemand := make([][]float64, len(in.Traffic))
+	var total []float64
+	for r := range reps {
+		c := cfg
+		c.Seed += int64(r)
+		n := NewNetwork(in)
+		alloc, err := NewAllocator(policy, n, c)
+		if err != nil {
+			return nil, Estimate{}, err
+		}
+		st := New(n, alloc, c).Run()
+		for ti := range in.Traffic {
+			perDemand[ti] = append(perDemand[ti], st.Demand(ti).Blocking())
+		}
+		t := st.Total()
+		total = append(total, t.Blocking())
+	}
+	es := make([]Estimate, len(perDemand))
+	for ti, xs := range perDemand {
+		es[ti] = estimate(xs)
+	}
+	return es, estimate(total), nil
+}
+
+// Write prints the estimate of r and the arrivals seen per region.
+func (r *RareResult) Write(w io.Writer) error {
+	rel := math.Inf(1)
+	if r.Blocking.Mean > 0 {
+		rel = r.Blocking.HalfWidth / r.Blocking.Mean
+	}
+	if _, err := fmt.Fprintf(w, "blocking %v (relative half-width %.2f), %d retrials, %d arrivals simulated\n",
+		r.Blocking, rel, r.Retrials, r.Simulated); err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "region\tarrivals\t")
+	for k, v := range r.Visits {
+		fmt.Fprintf(tw, "%d\t%d\t\n", k, v)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,31 @@
+package sim
+
+import (
+	"math"
+	"testing"
+
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+)
+
+func TestRare(t *testing.T) {
+	in, err := instance.Load("../testdata/line3.dat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, policy := range []string{"zone-flf", "first-fit"} {
+		t.Run(policy, func(t *testing.T) {
+			cfg := Config{Load: 2, Holding: 1, Arrivals: 4000, Warmup: 500, Seed: 1}
+			direct, err := Direct(in, policy, cfg, 5)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rare, err := Rare(in, policy, cfg, Splitting{Thresholds: []int{6, 9}, Factor: 3, Replications: 5})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d := math.Abs(rare.Blocking.Mean - direct.Mean); d > rare.Blocking.HalfWidth+direct.HalfWidth {
+				t.Errorf("splitting %v, direct %v", rare.Blocking, direct)
+			}
+		})
+	}
+}
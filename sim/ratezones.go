@@ -0,0 +1,129 @@
+package sim
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/dilwar-crnlab/hpsr_2025/spectrum"
+)
+
+// RateZones gives every bandwidth class, i.e. every distinct N_req, a
+// dedicated zone whose width is a multiple of the class' block width
+// N_req + G. All blocks of a zone then have the same size and first fit
+// packs them without leftover gaps. Placements name the zone of n.Zones
+// their start lies in; once the layout is set as the zones of the network
+// with SetZones, as NewAllocator does, placements, admission thresholds
+// and defragmentation refer to the rate zones, named rate<N_req>.
+type RateZones struct {
+	Zone map[int]spectrum.Zone // by N_req
+}
+
+// NewRateZones splits the spectrum of n among the bandwidth classes in
+// proportion to their offered spectrum, T_sd times the block width of the
+// preferred option of each demand. Classes that are only used as a
+// fallback get a single block. The zones of n are left as they are.
+func NewRateZones(n *Network) *RateZones {
+	weight := map[int]float64{}
+	for ti, opts := range n.Options {
+		for i, o := range opts {
+			if i == 0 {
+				weight[o.Slots] += float64(n.In.T[n.In.Traffic[ti]]) * float64(n.Width(o))
+			} else if _, ok := weight[o.Slots]; !ok {
+				weight[o.Slots] = 0
+			}
+		}
+	}
+	rates := make([]int, 0, len(weight))
+	var total float64
+	for r, w := range weight {
+		rates = append(rates, r)
+		total += w
+	}
+	sort.Ints(rates)
+
+	// Every class gets at least one block, the rest is shared by weight and
+	// rounded down to whole blocks; what remains goes to the heaviest class
+	// that can still use a whole block of it.
+	width := func(r int) int { return r + n.In.G }
+	size := map[int]int{}
+	free := n.Grid.Slots
+	for _, r := range rates {
+		size[r] = width(r)
+		free -= width(r)
+	}
+	if free > 0 && total > 0 {
+		share := free
+		for _, r := range rates {
+			k := int(float64(share)*weight[r]/total) / width(r)
+			size[r] += k * width(r)
+			free -= k * width(r)
+		}
+	}
+	byWeight := append([]int(nil), rates...)
+	sort.SliceStable(byWeight, func(a, b int) bool { return weight[byWeight[a]] > weight[byWeight[b]] })
+	for _, r := range byWeight {
+		for free >= width(r) {
+			size[r] += width(r)
+			free -= width(r)
+		}
+	}
+
+	rz := &RateZones{Zone: map[int]spectrum.Zone{}}
+	lo := 0
+	for _, r := range rates {
+		hi := lo + size[r]
+		if hi > n.Grid.Slots {
+			hi = n.Grid.Slots
+		}
+		rz.Zone[r] = spectrum.Zone{Name: fmt.Sprintf("rate%d", r), Lo: lo, Hi: hi}
+		lo = hi
+	}
+	return rz
+}
+
+// rates lists the bandwidth classes of a in increasing N_req.
+func (a *RateZones) rates() []int {
+	rates := make([]int, 0, len(a.Zone))
+	for r := range a.Zone {
+		rates = append(rates, r)
+	}
+	sort.Ints(rates)
+	return rates
+}
+
+// Layout lists the zones of a in increasing N_req.
+func (a *RateZones) Layout() []spectrum.Zone {
+	var zs []spectrum.Zone
+	for _, r := range a.rates() {
+		zs = append(zs, a.Zone[r])
+	}
+	return zs
+}
+
+func (a *RateZones) Name() string { return "rate-zones" }
+
+func (a *RateZones) Place(n *Network, r *Request) (Placement, bool) {
+	for oi, o := range n.Options[r.T] {
+		z, ok := a.Zone[o.Slots]
+		if !ok {
+			continue
+		}
+		w := n.Width(o)
+		if s := n.Grid.FirstFit(o.Links, w, z.Lo, z.Hi); s >= 0 {
+			return Placement{oi, n.zoneOf(s), Left, s, w}, true
+		}
+	}
+	return Placement{}, false
+}
+
+// WriteLayout prints the zone of each bandwidth class.
+func (a *RateZones) WriteLayout(w io.Writer) error {
+	for _, r := range a.rates() {
+		z := a.Zone[r]
+		if _, err := fmt.Fprintf(w, "N_req %d: slots [%d,%d)\n", r, z.Lo, z.Hi); err != nil {
+			return err
+		}
+	}
+	return nil
+}
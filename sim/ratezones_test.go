@@ -0,0 +1,56 @@
+package sim
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+	"github.com/dilwar-crnlab/hpsr_2025/spectrum"
+)
+
+func TestRateZones(t *testing.T) {
+	in, err := instance.Load("../testdata/crankback.dat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Only 2-slot blocks are preferred, so 4-slot blocks get a single one.
+	layout := []spectrum.Zone{{Name: "rate2", Lo: 0, Hi: 12}, {Name: "rate4", Lo: 12, Hi: 16}}
+	n := NewNetwork(in)
+	a := NewRateZones(n)
+	if got := a.Layout(); !reflect.DeepEqual(got, layout) {
+		t.Errorf("layout %v, want %v", got, layout)
+	}
+	if want := spectrum.Zones(in); !reflect.DeepEqual(n.Zones, want) {
+		t.Errorf("NewRateZones changed the zones of the network to %v", n.Zones)
+	}
+	check := func(n *Network, opt, start int, zone string) {
+		t.Helper()
+		pl, ok := a.Place(n, &Request{T: 0})
+		if !ok {
+			t.Fatal("not placed")
+		}
+		if pl.Opt != opt || pl.Start != start {
+			t.Errorf("option %d at slot %d, want option %d at slot %d", pl.Opt, pl.Start, opt, start)
+		}
+		if z := n.Zones[pl.Zone]; z.Name != zone || pl.Start < z.Lo || pl.Start >= z.Hi {
+			t.Errorf("slot %d placed in zone %v, want zone %s", pl.Start, z, zone)
+		}
+	}
+	// With the zones of the instance, [0,8) and [8,16), placements name
+	// those; once the layout is set, the rate zones.
+	n.Grid.Assign(n.Options[0][0].Links, 0, 10, 1000)
+	check(n, 0, 10, "2")
+	n.Grid.Assign(n.Options[0][0].Links, 10, 12, 1000)
+	check(n, 1, 12, "2")
+
+	n = NewNetwork(in)
+	if _, err := NewAllocator("rate-zones", n, Config{}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(n.Zones, layout) {
+		t.Errorf("NewAllocator set the zones %v, want %v", n.Zones, layout)
+	}
+	check(n, 0, 0, "rate2")
+	n.Grid.Assign(n.Options[0][0].Links, 0, 12, 1000)
+	check(n, 1, 12, "rate4")
+}
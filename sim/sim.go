@@ -0,0 +1,208 @@
+package sim
+
+import "container/heap"
+
+// Config parameterises a simulation run.
+type Config struct {
+	Load     float64 // offered load in Erlang
+	Holding  float64 // mean holding time
+	Arrivals int     // number of arrivals after the warm-up
+	Warmup   int     // arrivals simulated before statistics are collected
+	Seed     int64
+	// Antithetic draws every traffic variate from 1-U instead of U, giving
+	// the mirror image of the run with the same seed.
+	Antithetic bool
+
+	// Preempt enables preemption of lower-priority lightpaths; nil disables it.
+	Preempt *Preemption
+	// Admission enables spectrum reservation admission control; nil disables it.
+	Admission *Admission
+	// Crankback lists the steps that widen the allocator's view, in
+	// order, when the narrow primary attempt finds no placement (see
+	// StepPath and the other Step constants). Admission control applies
+	// to every attempt, and preemption is only tried once the last step
+	// failed.
+	Crankback []string
+	// Batch provisions arrivals in batches with the ILP; nil provisions
+	// every arrival on its own.
+	Batch *Batching
+}
+
+// DefaultConfig returns a configuration for a 100 Erlang, 10^5 arrival run.
+func DefaultConfig() Config {
+	return Config{Load: 100, Holding: 1, Arrivals: 100000, Warmup: 10000, Seed: 1}
+}
+
+// Sim is a single simulation run.
+type Sim struct {
+	Net   *Network
+	Alloc Allocator
+	Cfg   Config
+	Stats *Stats
+
+	traffic  *stream
+	now      float64
+	counting bool
+	nextID   int
+	pending  departures
+	buffer   []buffered // arrivals waiting for the end of their batch
+	batchEnd float64
+	cum      []float64 // cumulative T_sd for drawing demands
+	flf      *ZoneFLF  // connection types for crankback, see types
+}
+
+// New prepares a simulation of alloc on a fresh network of in.
+func New(n *Network, alloc Allocator, cfg Config) *Sim {
+	s := &Sim{
+		Net:     n,
+		Alloc:   alloc,
+		Cfg:     cfg,
+		Stats:   NewStats(alloc.Name(), n.In),
+		traffic: newStream(cfg.Seed, cfg.Antithetic),
+	}
+	if len(cfg.Crankback) > 0 {
+		s.Stats.Steps = append([]string{StepPrimary}, cfg.Crankback...)
+	}
+	var c float64
+	for _, t := range n.In.Traffic {
+		c += float64(n.In.T[t])
+		s.cum = append(s.cum, c)
+	}
+	return s
+}
+
+// Run simulates Warmup+Arrivals arrivals and returns the statistics of the
+// post warm-up period.
+func (s *Sim) Run() *Stats {
+	rate := s.Cfg.Load / s.Cfg.Holding
+	if s.Cfg.Batch != nil {
+		s.batchEnd = s.Cfg.Batch.Window
+	}
+	for i := 0; i < s.Cfg.Warmup+s.Cfg.Arrivals; i++ {
+		at := s.now + s.traffic.exp(1/rate)
+		s.batchesUntil(at)
+		s.now = at
+		s.departUntil(s.now)
+		s.counting = i >= s.Cfg.Warmup
+		s.arrive(s.request())
+	}
+	if len(s.buffer) > 0 {
+		s.batchesUntil(s.batchEnd)
+	}
+	return s.Stats
+}
+
+// batchesUntil provisions the batches whose windows end by t.
+func (s *Sim) batchesUntil(t float64) {
+	if s.Cfg.Batch == nil {
+		return
+	}
+	for s.batchEnd <= t {
+		s.now = s.batchEnd
+		s.departUntil(s.now)
+		s.provision()
+		s.batchEnd += s.Cfg.Batch.Window
+	}
+}
+
+// request draws the demand and holding time of a request arriving now.
+func (s *Sim) request() *Request {
+	r := &Request{
+		ID:      s.newID(),
+		T:       s.drawDemand(),
+		Arrival: s.now,
+		Holding: s.traffic.exp(s.Cfg.Holding),
+	}
+	r.Class = ClassOf(s.Net.In, r.T)
+	return r
+}
+
+func (s *Sim) newID() int {
+	s.nextID++
+	return s.nextID
+}
+
+func (s *Sim) drawDemand() int {
+	u := s.traffic.uniform() * s.cum[len(s.cum)-1]
+	for i, c := range s.cum {
+		if u < c {
+			return i
+		}
+	}
+	return len(s.cum) - 1
+}
+
+// count applies f to the statistics of the class and the demand of r once
+// the warm-up is over.
+func (s *Sim) count(r *Request, f func(*ClassStats)) {
+	if s.counting {
+		f(s.Stats.Class(r.Class))
+		f(s.Stats.Demand(r.T))
+	}
+}
+
+func (s *Sim) arrive(r *Request) {
+	s.count(r, func(c *ClassStats) { c.Arrivals++ })
+	if s.counting {
+		s.Stats.sampleFragmentation(s.Net.Grid.Fragmentation())
+	}
+	if s.Cfg.Batch != nil {
+		s.buffer = append(s.buffer, buffered{r, s.counting})
+		return
+	}
+	if len(s.Cfg.Crankback) > 0 {
+		ok, refused := s.crankback(r)
+		if ok {
+			return
+		}
+		if refused {
+			s.count(r, func(c *ClassStats) { c.Rejected++; c.Blocked++ })
+			return
+		}
+	} else if pl, ok := s.Alloc.Place(s.Net, r); ok {
+		if s.Cfg.Admission.admits(s.Net, r, pl) {
+			s.establish(r, pl)
+			return
+		}
+		s.count(r, func(c *ClassStats) { c.Rejected++; c.Blocked++ })
+		return
+	}
+	if s.Cfg.Preempt.allows(r.Class) && s.preempt(r) {
+		return
+	}
+	s.count(r, func(c *ClassStats) { c.Blocked++ })
+}
+
+func (s *Sim) establish(r *Request, pl Placement) {
+	s.Net.Establish(r, pl)
+	heap.Push(&s.pending, departure{r.Departure(), r.ID})
+}
+
+// departUntil tears down every lightpath departing at or before t.
+// Departures of lightpaths that were preempted and dropped are ignored.
+func (s *Sim) departUntil(t float64) {
+	for len(s.pending) > 0 && s.pending[0].at <= t {
+		d := heap.Pop(&s.pending).(departure)
+		if lp, ok := s.Net.Active[d.id]; ok {
+			s.Net.Teardown(lp)
+		}
+	}
+}
+
+type departure struct {
+	at float64
+	id int
+}
+
+type departures []departure
+
+func (h departures) Len() int           { return len(h) }
+func (h departures) Less(i, j int) bool { return h[i].at < h[j].at }
+func (h departures) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *departures) Push(x any)        { *h = append(*h, x.(departure)) }
+func (h *departures) Pop() any {
+	old := *h
+	d := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return d
+}
@@ -0,0 +1,202 @@
+package sim
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+)
+
+// DefaultClass labels requests of instances without CLASSES.
+const DefaultClass = "default"
+
+// ClassOf returns the service class of traffic index t.
+func ClassOf(in *instance.Instance, t int) string {
+	if c := in.ClassOf(in.Traffic[t]); c != "" {
+		return c
+	}
+	return DefaultClass
+}
+
+// ClassStats counts the outcome of the requests of one service class.
+type ClassStats struct {
+	Arrivals    int
+	Blocked     int
+	Rejected    int // blocked by admission control although spectrum was free
+	Preempting  int // arrivals established by preempting other lightpaths
+	Preempted   int // lightpaths of this class removed by a preemption
+	Reallocated int // preempted lightpaths that were re-established
+	Dropped     int // preempted lightpaths that could not be re-established
+}
+
+// Blocking is the fraction of arrivals that were blocked.
+func (c *ClassStats) Blocking() float64 {
+	if c.Arrivals == 0 {
+		return 0
+	}
+	return float64(c.Blocked) / float64(c.Arrivals)
+}
+
+// Loss is the fraction of arrivals that were blocked or dropped after a
+// preemption.
+func (c *ClassStats) Loss() float64 {
+	if c.Arrivals == 0 {
+		return 0
+	}
+	return float64(c.Blocked+c.Dropped) / float64(c.Arrivals)
+}
+
+func (c *ClassStats) addTo(t *ClassStats) {
+	t.Arrivals += c.Arrivals
+	t.Blocked += c.Blocked
+	t.Rejected += c.Rejected
+	t.Preempting += c.Preempting
+	t.Preempted += c.Preempted
+	t.Reallocated += c.Reallocated
+	t.Dropped += c.Dropped
+}
+
+// Stats collects the per-class results of a run.
+type Stats struct {
+	Policy  string
+	Classes []string // in decreasing priority
+	byClass map[string]*ClassStats
+	// Counters per traffic index, for the blocking of each (s,d) pair.
+	byDemand []ClassStats
+
+	fragSum     float64
+	fragSamples int
+
+	// Crankback step successes, for the primary attempt and each
+	// configured step in order.
+	Steps    []string
+	stepHits map[string]int
+	Moves    int // lightpaths moved by defragmentation
+
+	// Batches provisioned with the ILP and those among them whose solve
+	// stopped at a limit.
+	Batches, Unproven int
+	setupSum          float64
+	setupSamples      int
+}
+
+func (s *Stats) hitStep(step string, counting bool) {
+	if !counting {
+		return
+	}
+	s.stepHits[step]++
+}
+
+// StepHits is the number of requests established at crankback step.
+func (s *Stats) StepHits(step string) int { return s.stepHits[step] }
+
+func (s *Stats) moved(counting bool) {
+	if counting {
+		s.Moves++
+	}
+}
+
+func (s *Stats) sampleFragmentation(f float64) {
+	s.fragSum += f
+	s.fragSamples++
+}
+
+// Fragmentation is the mean external fragmentation seen by arrivals.
+func (s *Stats) Fragmentation() float64 {
+	if s.fragSamples == 0 {
+		return 0
+	}
+	return s.fragSum / float64(s.fragSamples)
+}
+
+func (s *Stats) sampleSetup(d float64) {
+	s.setupSum += d
+	s.setupSamples++
+}
+
+// Setup is the mean setup latency of the established requests.
+func (s *Stats) Setup() float64 {
+	if s.setupSamples == 0 {
+		return 0
+	}
+	return s.setupSum / float64(s.setupSamples)
+}
+
+// NewStats returns empty statistics for the classes of in.
+func NewStats(policy string, in *instance.Instance) *Stats {
+	s := &Stats{Policy: policy, byClass: map[string]*ClassStats{}, byDemand: make([]ClassStats, len(in.Traffic)),
+		stepHits: map[string]int{}}
+	s.Classes = append(s.Classes, in.Classes...)
+	if len(s.Classes) == 0 {
+		s.Classes = []string{DefaultClass}
+	}
+	for _, c := range s.Classes {
+		s.byClass[c] = &ClassStats{}
+	}
+	return s
+}
+
+// Class returns the counters of class c.
+func (s *Stats) Class(c string) *ClassStats {
+	cs, ok := s.byClass[c]
+	if !ok {
+		cs = &ClassStats{}
+		s.byClass[c] = cs
+		s.Classes = append(s.Classes, c)
+	}
+	return cs
+}
+
+// Demand returns the counters of traffic index t.
+func (s *Stats) Demand(t int) *ClassStats { return &s.byDemand[t] }
+
+// Total sums the counters over all classes.
+func (s *Stats) Total() ClassStats {
+	var t ClassStats
+	for _, c := range s.byClass {
+		c.addTo(&t)
+	}
+	return t
+}
+
+// Write prints a per-class table of s.
+func (s *Stats) Write(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "policy %s\n", s.Policy); err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "class\tarrivals\tblocked\trejected\tblocking\tpreempting\tpreempted\treallocated\tdropped\tloss\t")
+	row := func(name string, c *ClassStats) {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%.3e\t%d\t%d\t%d\t%d\t%.3e\t\n", name, c.Arrivals, c.Blocked, c.Rejected,
+			c.Blocking(), c.Preempting, c.Preempted, c.Reallocated, c.Dropped, c.Loss())
+	}
+	for _, c := range s.Classes {
+		row(c, s.byClass[c])
+	}
+	t := s.Total()
+	row("total", &t)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "mean fragmentation %.4f\n", s.Fragmentation()); err != nil {
+		return err
+	}
+	if s.Batches > 0 {
+		if _, err := fmt.Fprintf(w, "batches %d (%d not proven optimal), mean setup latency %.4g\n",
+			s.Batches, s.Unproven, s.Setup()); err != nil {
+			return err
+		}
+	}
+	if len(s.Steps) == 0 {
+		return nil
+	}
+	fmt.Fprintln(tw, "crankback step\tsuccesses\t")
+	for _, st := range s.Steps {
+		fmt.Fprintf(tw, "%s\t%d\t\n", st, s.stepHits[st])
+	}
+	if s.Moves > 0 {
+		fmt.Fprintf(tw, "defrag moves\t%d\t\n", s.Moves)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,36 @@
+package sim
+
+import (
+	"math"
+	"math/rand"
+)
+
+// stream is the random number stream of the traffic: inter-arrival times,
+// demands and holding times, three uniforms per arrival. Nothing else draws
+// from it, so runs with the same seed see identical arrivals whatever the
+// policy does with them (common random numbers). Every variate is drawn by
+// inversion from a single uniform, so that the antithetic stream, which
+// returns 1-U for every U, yields the negatively correlated mirror run.
+type stream struct {
+	rng  *rand.Rand
+	anti bool
+}
+
+func newStream(seed int64, antithetic bool) *stream {
+	return &stream{rand.New(rand.NewSource(seed)), antithetic}
+}
+
+// uniform returns a uniform variate in the open interval (0,1), on a grid
+// symmetric about 1/2 so that 1-U is drawn exactly as often as U.
+func (s *stream) uniform() float64 {
+	u := (float64(s.rng.Int63n(1<<53)) + 0.5) / (1 << 53)
+	if s.anti {
+		return 1 - u
+	}
+	return u
+}
+
+// exp returns an exponential variate of the given mean.
+func (s *stream) exp(mean float64) float64 {
+	return -mean * math.Log(s.uniform())
+}
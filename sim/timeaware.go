@@ -0,0 +1,69 @@
+package sim
+
+import (
+	"math"
+
+	"github.com/dilwar-crnlab/hpsr_2025/spectrum"
+)
+
+// TimeAware places a request, whose holding time is known at arrival, next
+// to lightpaths that depart at about the same time, so that neighbouring
+// blocks free up together and leave fewer gaps behind.
+//
+// Every free block of the first option that has one is scored over both
+// edges on every link of the path: an edge at a zone boundary costs 0, an
+// edge next to a lightpath costs the difference of the departure times in
+// units of the mean holding time (capped at Open), and an edge next to a
+// free slot costs Open. The block with the lowest total wins.
+type TimeAware struct {
+	Holding float64 // mean holding time used to normalise differences
+	Open    float64 // cost of an edge facing free spectrum
+}
+
+// NewTimeAware returns a time-aware allocator for mean holding time h.
+func NewTimeAware(h float64) *TimeAware {
+	return &TimeAware{Holding: h, Open: 1}
+}
+
+func (a *TimeAware) Name() string { return "time-aware" }
+
+func (a *TimeAware) Place(n *Network, r *Request) (Placement, bool) {
+	for oi, o := range n.Options[r.T] {
+		w := n.Width(o)
+		best, bestCost := Placement{}, math.Inf(1)
+		for zi, z := range n.Zones {
+			for s := z.Lo; s+w <= z.Hi; s++ {
+				if !n.Grid.IsFree(o.Links, s, s+w) {
+					continue
+				}
+				var cost float64
+				for _, li := range o.Links {
+					cost += a.edge(n, r, z, li, s-1) + a.edge(n, r, z, li, s+w)
+				}
+				if cost < bestCost {
+					best, bestCost = Placement{oi, zi, Left, s, w}, cost
+				}
+			}
+		}
+		if !math.IsInf(bestCost, 1) {
+			return best, true
+		}
+	}
+	return Placement{}, false
+}
+
+// edge is the cost of the slot s next to a candidate block of r in zone z.
+func (a *TimeAware) edge(n *Network, r *Request, z spectrum.Zone, li, s int) float64 {
+	if s < z.Lo || s >= z.Hi {
+		return 0
+	}
+	id := n.Grid.Owner(li, s)
+	if id == spectrum.Free {
+		return a.Open
+	}
+	lp, ok := n.Active[id]
+	if !ok {
+		return a.Open
+	}
+	return math.Min(math.Abs(lp.Departure()-r.Departure())/a.Holding, a.Open)
+}
@@ -0,0 +1,41 @@
+package solve
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+	"github.com/dilwar-crnlab/hpsr_2025/milp"
+	"github.com/dilwar-crnlab/hpsr_2025/model"
+)
+
+// milpEngine solves a formulation of package model by branch and bound.
+// It models the physical problem: per-link spectrum disjointness and
+// blocks confined to zones, like the cp engine.
+type milpEngine struct {
+	name string
+	gen  model.Generator
+}
+
+func (e milpEngine) Name() string { return e.name }
+
+func (e milpEngine) Solve(in *instance.Instance, opt Options) (Result, error) {
+	f := e.gen(in, model.Options{PerLink: true, Zones: true})
+	o := milp.Options{NodeLimit: opt.Budget}
+	if opt.TimeLimit > 0 {
+		o.Deadline = time.Now().Add(opt.TimeLimit)
+	}
+	r := milp.Solve(f.MILP, o)
+	switch r.Status {
+	case milp.Infeasible, milp.Unbounded:
+		return Result{Nodes: r.Nodes}, fmt.Errorf("formulation is %v", r.Status)
+	case milp.Limit:
+		return Result{Nodes: r.Nodes}, nil
+	}
+	return Result{Plan: f.Decode(r.X), Optimal: r.Status == milp.Optimal, Nodes: r.Nodes}, nil
+}
+
+func init() {
+	Register(milpEngine{"bigm", model.BigM})
+	Register(milpEngine{"slot", model.SlotIndexed})
+}
@@ -0,0 +1,132 @@
+// Package solve runs the solve engines available for an instance of
+// ilp.mod behind a common interface.
+package solve
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/dilwar-crnlab/hpsr_2025/benders"
+	"github.com/dilwar-crnlab/hpsr_2025/conflict"
+	"github.com/dilwar-crnlab/hpsr_2025/cp"
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+	"github.com/dilwar-crnlab/hpsr_2025/plan"
+	"github.com/dilwar-crnlab/hpsr_2025/sat"
+)
+
+// Options limits the effort of an engine.
+type Options struct {
+	Budget    int           // search nodes, <= 0 for no limit
+	TimeLimit time.Duration // <= 0 for no limit
+}
+
+// Result is the plan found by an engine.
+type Result struct {
+	Plan    plan.Plan
+	Optimal bool // proven optimal for the engine's own model
+	Nodes   int
+	Elapsed time.Duration
+}
+
+// Engine solves an instance.
+type Engine interface {
+	Name() string
+	Solve(in *instance.Instance, opt Options) (Result, error)
+}
+
+var engines = map[string]Engine{}
+
+// Register makes e available to Lookup under its name.
+func Register(e Engine) { engines[e.Name()] = e }
+
+// Lookup returns the engine registered under name.
+func Lookup(name string) (Engine, error) {
+	e, ok := engines[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown engine %q (have %v)", name, Names())
+	}
+	return e, nil
+}
+
+// Names lists the registered engines.
+func Names() []string {
+	ns := make([]string, 0, len(engines))
+	for n := range engines {
+		ns = append(ns, n)
+	}
+	sort.Strings(ns)
+	return ns
+}
+
+// Run solves in with the engine registered under name and times it.
+func Run(name string, in *instance.Instance, opt Options) (Result, error) {
+	e, err := Lookup(name)
+	if err != nil {
+		return Result{}, err
+	}
+	t0 := time.Now()
+	res, err := e.Solve(in, opt)
+	res.Elapsed = time.Since(t0)
+	if err != nil {
+		return res, fmt.Errorf("%s: %w", name, err)
+	}
+	res.Plan.Sort(in)
+	return res, nil
+}
+
+func init() {
+	Register(engineFunc{"ffd", func(in *instance.Instance, _ Options) (Result, error) {
+		return Result{Plan: plan.FirstFitDecreasingZones(in)}, nil
+	}})
+	Register(engineFunc{"dsatur", func(in *instance.Instance, _ Options) (Result, error) {
+		return Result{Plan: conflict.Build(in, plan.Shortest(in)).DSatur()}, nil
+	}})
+	Register(engineFunc{"color", func(in *instance.Instance, opt Options) (Result, error) {
+		g := conflict.Build(in, plan.Shortest(in))
+		p, found, optimal := g.Exact(opt.Budget)
+		if !found {
+			return Result{Plan: g.DSatur()}, nil
+		}
+		return Result{Plan: p, Optimal: optimal}, nil
+	}})
+	Register(engineFunc{"cp", func(in *instance.Instance, opt Options) (Result, error) {
+		o := cp.Options{Budget: opt.Budget}
+		if opt.TimeLimit > 0 {
+			o.Deadline = time.Now().Add(opt.TimeLimit)
+		}
+		r := cp.Solve(in, o)
+		return Result{Plan: r.Plan, Optimal: r.Optimal, Nodes: r.Nodes}, nil
+	}})
+	Register(engineFunc{"benders", func(in *instance.Instance, opt Options) (Result, error) {
+		o := benders.Options{Budget: opt.Budget}
+		if opt.TimeLimit > 0 {
+			o.Deadline = time.Now().Add(opt.TimeLimit)
+		}
+		r := benders.Solve(in, o)
+		return Result{Plan: r.Plan, Optimal: r.Optimal, Nodes: r.Nodes}, nil
+	}})
+	Register(engineFunc{"maxsat", func(in *instance.Instance, opt Options) (Result, error) {
+		var deadline time.Time
+		if opt.TimeLimit > 0 {
+			deadline = time.Now().Add(opt.TimeLimit)
+		}
+		e := sat.Encode(in, sat.Options{})
+		r := sat.SolveMax(e.CNF, deadline)
+		if r.Status == sat.Unsatisfiable {
+			return Result{Nodes: r.Conflicts}, fmt.Errorf("encoding is unsatisfiable")
+		}
+		return Result{Plan: e.Decode(r.Model), Optimal: r.Optimal, Nodes: r.Conflicts}, nil
+	}})
+}
+
+type engineFunc struct {
+	name  string
+	solve func(*instance.Instance, Options) (Result, error)
+}
+
+func (e engineFunc) Name() string { return e.name }
+
+func (e engineFunc) Solve(in *instance.Instance, opt Options) (Result, error) {
+	return e.solve(in, opt)
+}
@@ -0,0 +1,184 @@
+// Package spectrum tracks per-link frequency slot occupancy and the zone
+// layout used by the zone-FLF allocation of ilp.mod.
+package spectrum
+
+import "github.com/dilwar-crnlab/hpsr_2025/instance"
+
+// Free marks an unoccupied slot in a Grid.
+const Free = 0
+
+// Grid is the slot occupancy of every link. Each slot holds the id of the
+// lightpath occupying it, or Free.
+type Grid struct {
+	Slots int
+	Links []instance.Link
+	index map[instance.Link]int
+	occ   [][]int
+}
+
+// NewGrid returns an empty grid over the links of in with N_slots slots.
+func NewGrid(in *instance.Instance) *Grid {
+	g := &Grid{
+		Slots: in.NSlots,
+		Links: in.Links,
+		index: make(map[instance.Link]int, len(in.Links)),
+		occ:   make([][]int, len(in.Links)),
+	}
+	for i, l := range in.Links {
+		g.index[l] = i
+		g.index[l.Reverse()] = i
+		g.occ[i] = make([]int, in.NSlots)
+	}
+	return g
+}
+
+// Clone returns an independent copy of g.
+func (g *Grid) Clone() *Grid {
+	c := &Grid{Slots: g.Slots, Links: g.Links, index: g.index, occ: make([][]int, len(g.occ))}
+	for i, row := range g.occ {
+		c.occ[i] = append([]int(nil), row...)
+	}
+	return c
+}
+
+// LinkIndex returns the row of l (in either orientation).
+func (g *Grid) LinkIndex(l instance.Link) (int, bool) {
+	i, ok := g.index[l]
+	return i, ok
+}
+
+// Indices maps a path given as links to grid rows. Unknown links are skipped.
+func (g *Grid) Indices(ls []instance.Link) []int {
+	idx := make([]int, 0, len(ls))
+	for _, l := range ls {
+		if i, ok := g.index[l]; ok {
+			idx = append(idx, i)
+		}
+	}
+	return idx
+}
+
+// Owner returns the lightpath id occupying slot s of link row li.
+func (g *Grid) Owner(li, s int) int { return g.occ[li][s] }
+
+// IsFree reports whether slots [lo,hi) are free on every link in links.
+func (g *Grid) IsFree(links []int, lo, hi int) bool {
+	if lo < 0 || hi > g.Slots {
+		return false
+	}
+	for _, li := range links {
+		row := g.occ[li]
+		for s := lo; s < hi; s++ {
+			if row[s] != Free {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Assign marks slots [lo,hi) of every link in links as owned by id.
+func (g *Grid) Assign(links []int, lo, hi, id int) {
+	for _, li := range links {
+		row := g.occ[li]
+		for s := lo; s < hi; s++ {
+			row[s] = id
+		}
+	}
+}
+
+// Release frees slots [lo,hi) of every link in links.
+func (g *Grid) Release(links []int, lo, hi int) {
+	g.Assign(links, lo, hi, Free)
+}
+
+// FirstFit returns the lowest start s in [lo,hi-w] such that [s,s+w) is free
+// on all links, or -1.
+func (g *Grid) FirstFit(links []int, w, lo, hi int) int {
+	for s := lo; s+w <= hi; s++ {
+		if g.IsFree(links, s, s+w) {
+			return s
+		}
+	}
+	return -1
+}
+
+// LastFit returns the highest start s in [lo,hi-w] such that [s,s+w) is free
+// on all links, or -1.
+func (g *Grid) LastFit(links []int, w, lo, hi int) int {
+	for s := hi - w; s >= lo; s-- {
+		if g.IsFree(links, s, s+w) {
+			return s
+		}
+	}
+	return -1
+}
+
+// Used returns the number of occupied slots summed over all links.
+func (g *Grid) Used() int {
+	n := 0
+	for _, row := range g.occ {
+		for _, o := range row {
+			if o != Free {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// MaxUsed returns the number of occupied slots on the most occupied link.
+func (g *Grid) MaxUsed() int {
+	best := 0
+	for _, row := range g.occ {
+		n := 0
+		for _, o := range row {
+			if o != Free {
+				n++
+			}
+		}
+		best = max(best, n)
+	}
+	return best
+}
+
+// LargestFree returns the longest run of slots in [lo,hi) that is free on
+// every link in links.
+func (g *Grid) LargestFree(links []int, lo, hi int) int {
+	best, run := 0, 0
+	for s := lo; s < hi; s++ {
+		if g.IsFree(links, s, s+1) {
+			run++
+			if run > best {
+				best = run
+			}
+		} else {
+			run = 0
+		}
+	}
+	return best
+}
+
+// Fragmentation is the external fragmentation 1 - largest free run / free
+// slots, averaged over the links with free spectrum.
+func (g *Grid) Fragmentation() float64 {
+	var sum float64
+	n := 0
+	for li := range g.occ {
+		free := 0
+		for _, o := range g.occ[li] {
+			if o == Free {
+				free++
+			}
+		}
+		if free == 0 {
+			continue
+		}
+		sum += 1 - float64(g.LargestFree([]int{li}, 0, g.Slots))/float64(free)
+		n++
+	}
+	if n == 0 {
+		return 0
+	}
+	return sum / float64(n)
+}
@@ -0,0 +1,33 @@
+package spectrum
+
+import "github.com/dilwar-crnlab/hpsr_2025/instance"
+
+// Zone is a contiguous slot range [Lo,Hi) of the spectrum.
+type Zone struct {
+	Name   string
+	Lo, Hi int
+}
+
+// Width is the number of slots of z.
+func (z Zone) Width() int { return z.Hi - z.Lo }
+
+// Zones lays out ZONES side by side from slot 0 with widths C_z, clipping
+// the layout at N_slots. Zones that fall entirely beyond N_slots are dropped.
+func Zones(in *instance.Instance) []Zone {
+	var zs []Zone
+	lo := 0
+	for _, name := range in.Zones {
+		hi := lo + in.CZ[name]
+		if hi > in.NSlots {
+			hi = in.NSlots
+		}
+		if hi > lo {
+			zs = append(zs, Zone{name, lo, hi})
+		}
+		lo = hi
+	}
+	if len(zs) == 0 {
+		zs = append(zs, Zone{"all", 0, in.NSlots})
+	}
+	return zs
+}
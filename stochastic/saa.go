@@ -0,0 +1,120 @@
+package stochastic
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+)
+
+// SAAOptions controls the sample-average approximation.
+type SAAOptions struct {
+	Samples      int // scenarios per replication
+	Replications int
+	Eval         int // scenarios of the out-of-sample evaluation
+	Seed         int64
+	Options
+}
+
+// Estimate is a sample mean with the half-width of its 95% confidence
+// interval.
+type Estimate struct {
+	Mean, HalfWidth float64
+	N               int
+}
+
+func estimate(xs []float64) Estimate {
+	e := Estimate{N: len(xs)}
+	for _, x := range xs {
+		e.Mean += x
+	}
+	e.Mean /= float64(len(xs))
+	if len(xs) < 2 {
+		return e
+	}
+	v := 0.0
+	for _, x := range xs {
+		v += (x - e.Mean) * (x - e.Mean)
+	}
+	e.HalfWidth = t95(len(xs)-1) * math.Sqrt(v/float64(len(xs)-1)/float64(len(xs)))
+	return e
+}
+
+// t95 is the two-sided 95% quantile of Student's t with df degrees of
+// freedom.
+func t95(df int) float64 {
+	table := []float64{12.706, 4.303, 3.182, 2.776, 2.571, 2.447, 2.365, 2.306, 2.262, 2.228,
+		2.201, 2.179, 2.160, 2.145, 2.131, 2.120, 2.110, 2.101, 2.093, 2.086,
+		2.080, 2.074, 2.069, 2.064, 2.060, 2.056, 2.052, 2.048, 2.045, 2.042}
+	if df <= len(table) {
+		return table[df-1]
+	}
+	return 1.96
+}
+
+// Candidate is the first stage of one replication.
+type Candidate struct {
+	First    map[instance.Demand]Choice
+	InSample float64 // optimal value of its replication
+	// OutOfSample is the expected weight of First on the evaluation
+	// scenarios, after layout.
+	OutOfSample Estimate
+}
+
+// SAAResult is the outcome of SAA.
+type SAAResult struct {
+	Candidates []Candidate
+	// Upper estimates an upper bound on the optimal expected weight: the
+	// mean branch-and-bound bound of the replications, which is their
+	// optimal value when they are solved to optimality and overestimates
+	// the optimal expected weight in expectation.
+	Upper Estimate
+	// Best is the candidate with the highest out-of-sample mean, the
+	// estimate of a lower bound.
+	Best int
+}
+
+// Lower is the out-of-sample estimate of the best candidate, or the zero
+// Estimate when no replication found a solution.
+func (r SAAResult) Lower() Estimate {
+	if len(r.Candidates) == 0 {
+		return Estimate{}
+	}
+	return r.Candidates[r.Best].OutOfSample
+}
+
+// SAA solves Replications independent sample-average problems of Samples
+// scenarios drawn by Sample and evaluates the first stage of each on the
+// same Eval fresh scenarios. Replications that stop without a solution
+// give neither a candidate nor a value of Upper.
+func SAA(in *instance.Instance, opt SAAOptions) SAAResult {
+	rng := rand.New(rand.NewSource(opt.Seed))
+	var res SAAResult
+	var values []float64
+	for range opt.Replications {
+		r := Solve(in, Sample(in, opt.Samples, rng), opt.Options)
+		if r.First == nil {
+			continue
+		}
+		values = append(values, r.Bound)
+		res.Candidates = append(res.Candidates, Candidate{First: r.First, InSample: r.Expected})
+	}
+	if len(values) == 0 {
+		return res
+	}
+	res.Upper = estimate(values)
+	eval := Sample(in, opt.Eval, rng)
+	for i := range res.Candidates {
+		c := &res.Candidates[i]
+		var ws []float64
+		for _, s := range eval {
+			s.Prob = 1
+			ws = append(ws, Evaluate(in, c.First, []Scenario{s}, opt.Options).Realized)
+		}
+		c.OutOfSample = estimate(ws)
+		if c.OutOfSample.Mean > res.Candidates[res.Best].OutOfSample.Mean {
+			res.Best = i
+		}
+	}
+	return res
+}
@@ -0,0 +1,344 @@
+// Package stochastic plans for traffic given as scenarios with
+// probabilities, as a two-stage stochastic program. The first stage
+// reserves for every request one candidate path and one spectrum zone,
+// before the traffic is known:
+//
+//	sum over p, z of Y[t,p,z] <= 1.
+//
+// In each scenario w the second stage accepts requests on their reserved
+// path and zone, with any reach-feasible modulation and the N_req of the
+// scenario's T_sd:
+//
+//	sum over m of X[t,p,m,z,w] <= Y[t,p,z]
+//	sum over t,p,m using l of (N_req[t,p,m,w] + G) X[t,p,m,z,w] <= |z| (+ G in the last zone)
+//
+// and the expected accepted weight sum Prob[w] W[t] X[t,p,m,z,w] is
+// maximised. The capacity rows bound the load of every link and zone; the
+// blocks of a scenario are then laid out greedily inside their zones.
+package stochastic
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+	"github.com/dilwar-crnlab/hpsr_2025/milp"
+	"github.com/dilwar-crnlab/hpsr_2025/model"
+	"github.com/dilwar-crnlab/hpsr_2025/plan"
+	"github.com/dilwar-crnlab/hpsr_2025/spectrum"
+	"github.com/dilwar-crnlab/hpsr_2025/units"
+)
+
+// Scenario is one realization of the traffic.
+type Scenario struct {
+	Name string
+	Prob float64
+	T    map[instance.Demand]units.DataRate
+}
+
+// Scenarios returns the scenarios of in: SCENARIOS with Prob and T_scen,
+// or the forecast T_sd as a single scenario when there are none. A
+// scenario without Prob has 1/card(SCENARIOS), as in ilp.mod, and the
+// probabilities are normalised to sum to one.
+func Scenarios(in *instance.Instance) []Scenario {
+	if len(in.Scenarios) == 0 {
+		return []Scenario{{Name: "forecast", Prob: 1, T: in.T}}
+	}
+	prob := func(w string) float64 {
+		if p, ok := in.Prob[w]; ok {
+			return p
+		}
+		return 1 / float64(len(in.Scenarios))
+	}
+	sum := 0.0
+	for _, w := range in.Scenarios {
+		sum += prob(w)
+	}
+	var ss []Scenario
+	for _, w := range in.Scenarios {
+		s := Scenario{Name: w, Prob: 1 / float64(len(in.Scenarios)), T: map[instance.Demand]units.DataRate{}}
+		if sum > 0 {
+			s.Prob = prob(w) / sum
+		}
+		for _, t := range in.Traffic {
+			s.T[t] = in.ScenarioT(w, t)
+		}
+		ss = append(ss, s)
+	}
+	return ss
+}
+
+// Sample draws n equally likely scenarios: from the scenarios of in by
+// their probabilities when it has any, otherwise with every T_sd uniform
+// in [T_sd - T_hat, T_sd + T_hat] (and positive).
+func Sample(in *instance.Instance, n int, rng *rand.Rand) []Scenario {
+	var ss []Scenario
+	if len(in.Scenarios) > 0 {
+		from := Scenarios(in)
+		for i := range n {
+			u, k := rng.Float64(), 0
+			for k < len(from)-1 && u >= from[k].Prob {
+				u -= from[k].Prob
+				k++
+			}
+			ss = append(ss, Scenario{Name: fmt.Sprintf("s%d:%s", i+1, from[k].Name), Prob: 1 / float64(n), T: from[k].T})
+		}
+		return ss
+	}
+	for i := range n {
+		s := Scenario{Name: fmt.Sprintf("s%d", i+1), Prob: 1 / float64(n), T: map[instance.Demand]units.DataRate{}}
+		for _, t := range in.Traffic {
+			v := in.T[t] + in.THat[t].Scale(2*rng.Float64()-1)
+			s.T[t] = max(v, 1e-6*in.T[t])
+		}
+		ss = append(ss, s)
+	}
+	return ss
+}
+
+// Instance returns a copy of in with the traffic of s, N_req re-derived
+// from the slot efficiencies eff (see instance.Efficiency) where T_sd
+// differs.
+func (s Scenario) Instance(in *instance.Instance, eff map[string]float64) *instance.Instance {
+	c := in.Clone()
+	for t, v := range s.T {
+		c.T[t] = v
+	}
+	for k := range c.NReq {
+		if c.T[k.T] != in.T[k.T] {
+			c.NReq[k] = c.Slots(k.T, eff[k.M])
+		}
+	}
+	return c
+}
+
+// Choice is the first-stage decision for a request: its path and zone.
+type Choice struct {
+	P    string
+	Zone string
+}
+
+// Options controls the solves.
+type Options struct {
+	NodeLimit int       // branch-and-bound nodes, <= 0 for no limit
+	Deadline  time.Time // zero for none
+}
+
+// Result is a two-stage plan.
+type Result struct {
+	Status milp.Status
+	// First is the first stage, nil when branch and bound stopped without
+	// a solution.
+	First map[instance.Demand]Choice
+	// Expected is the expected accepted weight of the program and Bound
+	// the upper bound on it left by branch and bound.
+	Expected, Bound float64
+	Scenarios       []Scenario
+	// Plans are the spectrum assignments per scenario; Realized is their
+	// expected weight, below Expected when a layout leaves out requests
+	// the capacity rows admitted.
+	Plans    []plan.Plan
+	Realized float64
+}
+
+// The objective charges reserveCost per reservation and scales the weight
+// of a block of n slots by 1 - slotTie n/N_slots, both only to break ties.
+const (
+	reserveCost = 1e-6
+	slotTie     = 1e-4
+)
+
+type reserve struct {
+	t instance.Demand
+	c Choice
+	z spectrum.Zone
+	v int // Y variable
+}
+
+type accept struct {
+	t instance.Demand
+	r plan.Route
+	z spectrum.Zone
+	w int // scenario
+	v int // X variable
+}
+
+type program struct {
+	*model.MILP
+	ins []*instance.Instance // per scenario
+	ys  []reserve
+	xs  []accept
+}
+
+// build generates the program of scens. A non-nil fixed pins the first
+// stage to it.
+func build(in *instance.Instance, scens []Scenario, fixed map[instance.Demand]Choice) *program {
+	pr := &program{MILP: model.NewMILP("stochastic")}
+	eff := in.Efficiency()
+	for _, s := range scens {
+		pr.ins = append(pr.ins, s.Instance(in, eff))
+	}
+	zones := spectrum.Zones(in)
+	type cell struct {
+		l instance.Link
+		z string
+		w int
+	}
+	load := map[cell][]model.Term{}
+	for _, t := range in.Traffic {
+		var one []model.Term
+		for _, p := range in.Paths[t] {
+			for _, z := range zones {
+				c := Choice{p, z.Name}
+				if f, ok := fixed[t]; fixed != nil && (!ok || f != c) {
+					continue
+				}
+				y := -1
+				reserved := make([][]model.Term, len(scens))
+				for w, s := range scens {
+					for _, r := range plan.Candidates(pr.ins[w], t) {
+						n := plan.Slots(pr.ins[w], t, r)
+						if r.P != p || n > z.Width() {
+							continue
+						}
+						if y < 0 {
+							y = pr.AddVar(fmt.Sprintf("Y(%s,%s,%s,%s)", t.S, t.D, p, z.Name), model.Binary, 0, 1)
+							// Reserve nothing that no scenario uses.
+							pr.Maximize(model.Term{Var: y, Coef: -reserveCost})
+							one = append(one, model.Term{Var: y, Coef: 1})
+							pr.ys = append(pr.ys, reserve{t, c, z, y})
+						}
+						x := pr.AddVar(fmt.Sprintf("X(%s,%s,%s,%s,%s,%s)", t.S, t.D, p, r.M, z.Name, s.Name), model.Binary, 0, 1)
+						// Ties go to fewer slots.
+						pr.Maximize(model.Term{Var: x, Coef: s.Prob * in.Weight(t) * (1 - slotTie*float64(n)/float64(in.NSlots))})
+						pr.xs = append(pr.xs, accept{t, r, z, w, x})
+						reserved[w] = append(reserved[w], model.Term{Var: x, Coef: 1})
+						for _, l := range plan.Links(in, t, r) {
+							k := cell{l, z.Name, w}
+							load[k] = append(load[k], model.Term{Var: x, Coef: float64(n + in.G)})
+						}
+					}
+				}
+				if y < 0 {
+					continue
+				}
+				if fixed != nil {
+					pr.Vars[y].Lo = 1
+				}
+				for w, terms := range reserved {
+					if len(terms) > 0 {
+						pr.AddRow(fmt.Sprintf("Reserved(%s,%s,%s,%s,%s)", t.S, t.D, p, z.Name, scens[w].Name),
+							append(terms, model.Term{Var: y, Coef: -1}), model.LE, 0)
+					}
+				}
+			}
+		}
+		if len(one) > 0 {
+			pr.AddRow(fmt.Sprintf("OneChoice(%s,%s)", t.S, t.D), one, model.LE, 1)
+		}
+	}
+	for w, s := range scens {
+		for _, z := range zones {
+			room := float64(z.Width())
+			if z.Hi == in.NSlots {
+				// The guard band of the last block would fall past N_slots.
+				room += float64(in.G)
+			}
+			for _, l := range in.Links {
+				if terms := load[cell{l, z.Name, w}]; len(terms) > 0 {
+					pr.AddRow(fmt.Sprintf("Capacity(%s,%s,%s)", l, z.Name, s.Name), terms, model.LE, room)
+				}
+			}
+		}
+	}
+	return pr
+}
+
+// Solve computes a two-stage plan of in over scens.
+func Solve(in *instance.Instance, scens []Scenario, opt Options) Result {
+	return run(in, scens, nil, opt)
+}
+
+// Evaluate fixes the first stage of in to first and solves the second
+// stage of every scenario on its own.
+func Evaluate(in *instance.Instance, first map[instance.Demand]Choice, scens []Scenario, opt Options) Result {
+	res := Result{Status: milp.Optimal, First: first, Scenarios: scens}
+	for _, s := range scens {
+		one := s
+		one.Prob = 1
+		r := run(in, []Scenario{one}, first, opt)
+		if r.Status != milp.Optimal {
+			res.Status = r.Status
+		}
+		res.Expected += s.Prob * r.Expected
+		res.Bound += s.Prob * r.Bound
+		res.Plans = append(res.Plans, r.Plans...)
+		res.Realized += s.Prob * r.Realized
+	}
+	return res
+}
+
+func run(in *instance.Instance, scens []Scenario, fixed map[instance.Demand]Choice, opt Options) Result {
+	pr := build(in, scens, fixed)
+	r := milp.Solve(pr.MILP, milp.Options{NodeLimit: opt.NodeLimit, Deadline: opt.Deadline})
+	// Undo the perturbations of the objective so that Bound bounds the
+	// accepted weight.
+	bound := (r.Bound + reserveCost*float64(len(pr.ys))) / (1 - slotTie)
+	res := Result{Status: r.Status, Bound: bound, Scenarios: scens}
+	if r.X == nil {
+		return res
+	}
+	res.First = map[instance.Demand]Choice{}
+	for _, y := range pr.ys {
+		if r.X[y.v] > 0.5 {
+			res.First[y.t] = y.c
+		}
+	}
+	if fixed != nil {
+		res.First = fixed
+	}
+	byScen := make([][]accept, len(scens))
+	for _, a := range pr.xs {
+		if r.X[a.v] > 0.5 {
+			byScen[a.w] = append(byScen[a.w], a)
+			res.Expected += scens[a.w].Prob * in.Weight(a.t)
+		}
+	}
+	for w, s := range scens {
+		p := layout(pr.ins[w], byScen[w])
+		res.Plans = append(res.Plans, p)
+		res.Realized += s.Prob * p.Weight(in)
+	}
+	return res
+}
+
+// layout assigns the accepted requests of a scenario, largest first, to
+// the lowest block inside their zone that keeps the guard band to the
+// blocks on shared links. Requests that do not fit are left out.
+func layout(in *instance.Instance, as []accept) plan.Plan {
+	sort.SliceStable(as, func(i, j int) bool {
+		return plan.Slots(in, as[i].t, as[i].r) > plan.Slots(in, as[j].t, as[j].r)
+	})
+	var p plan.Plan
+	for _, a := range as {
+		n := plan.Slots(in, a.t, a.r)
+		for s := a.z.Lo; s+n <= a.z.Hi; s++ {
+			b := plan.Assignment{T: a.t, Route: a.r, Start: s, Slots: n}
+			ok := true
+			for _, o := range p {
+				if plan.Share(in, o, b) && !plan.Apart(o, b, in.G) {
+					ok = false
+					break
+				}
+			}
+			if ok {
+				p = append(p, b)
+				break
+			}
+		}
+	}
+	p.Sort(in)
+	return p
+}
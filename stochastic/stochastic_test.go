@@ -0,0 +1,56 @@
+package stochastic
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+)
+
+func TestScenarios(t *testing.T) {
+	in, err := instance.Load("../testdata/scenarios.dat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// lo has 0.5, mid and hi 1/3 each, normalised by 7/6.
+	want := map[string]float64{"lo": 3.0 / 7, "mid": 2.0 / 7, "hi": 2.0 / 7}
+	ss := Scenarios(in)
+	if len(ss) != len(want) {
+		t.Fatalf("%d scenarios, want %d", len(ss), len(want))
+	}
+	for _, s := range ss {
+		if math.Abs(s.Prob-want[s.Name]) > 1e-12 {
+			t.Errorf("scenario %s has probability %g, want %g", s.Name, s.Prob, want[s.Name])
+		}
+	}
+}
+
+func TestSAA(t *testing.T) {
+	in, err := instance.Load("../testdata/scenarios.dat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opt := SAAOptions{Samples: 4, Replications: 3, Eval: 20, Seed: 1}
+	r := SAA(in, opt)
+	if len(r.Candidates) != opt.Replications || r.Upper.N != opt.Replications {
+		t.Fatalf("%d candidates and %d upper values, want %d", len(r.Candidates), r.Upper.N, opt.Replications)
+	}
+	in1 := 0.0
+	for _, c := range r.Candidates {
+		in1 += c.InSample
+	}
+	if in1 /= float64(len(r.Candidates)); r.Upper.Mean < in1-1e-9 {
+		t.Errorf("upper estimate %g below the mean in-sample value %g", r.Upper.Mean, in1)
+	}
+	if lo := r.Lower(); lo.N != opt.Eval || lo.Mean > r.Upper.Mean+r.Upper.HalfWidth {
+		t.Errorf("lower estimate %+v, upper %+v", lo, r.Upper)
+	}
+
+	// Replications stopped before finding a solution are left out.
+	opt.Deadline = time.Now().Add(-time.Second)
+	r = SAA(in, opt)
+	if len(r.Candidates) != 0 || r.Upper.N != 0 || r.Lower() != (Estimate{}) {
+		t.Errorf("without solutions: %d candidates, upper %+v, lower %+v", len(r.Candidates), r.Upper, r.Lower())
+	}
+}
@@ -0,0 +1,492 @@
+// Package topology generates random and regular network topologies with
+// node coordinates and fibre lengths, to test the planners beyond the few
+// reference networks. Nodes are placed on the earth's surface, and a link
+// is as long as the great-circle distance between its ends times a route
+// factor, since fibre follows roads and rights of way rather than the
+// geodesic. Every generated topology is connected and, with at least
+// three nodes, 2-edge-connected: it has no bridge, so that all nodes stay
+// connected after any single link failure. Minimum degree 2 alone would not
+// do, as two rings joined by one link show.
+package topology
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+
+	"github.com/dilwar-crnlab/hpsr_2025/instance"
+	"github.com/dilwar-crnlab/hpsr_2025/units"
+)
+
+// earthRadius is the mean earth radius in km.
+const earthRadius = 6371.0
+
+// Node is a node with its position in degrees.
+type Node struct {
+	Name     string
+	Lat, Lon float64
+}
+
+// Topology is a generated network.
+type Topology struct {
+	Model string
+	Nodes []Node
+	Links [][2]int // indices into Nodes, the lower first
+	// RouteFactor scales the great-circle distances into fibre lengths.
+	RouteFactor float64
+}
+
+// Options are the parameters shared by the generators.
+type Options struct {
+	N           int            // nodes
+	Seed        int64          // random placement and wiring
+	Lat, Lon    float64        // centre of the region, degrees
+	Span        units.Distance // side of the square region
+	RouteFactor float64        // fibre length over great-circle distance, >= 1
+}
+
+// DefaultOptions returns 20 nodes in a 2000 km region around central
+// Europe with a route factor of 1.5.
+func DefaultOptions() Options {
+	return Options{N: 20, Seed: 1, Lat: 50, Lon: 10, Span: 2000, RouteFactor: 1.5}
+}
+
+// Distance is the great-circle distance between a and b.
+func Distance(a, b Node) units.Distance {
+	rad := math.Pi / 180
+	dlat, dlon := (b.Lat-a.Lat)*rad, (b.Lon-a.Lon)*rad
+	h := math.Sin(dlat/2)*math.Sin(dlat/2) + math.Cos(a.Lat*rad)*math.Cos(b.Lat*rad)*math.Sin(dlon/2)*math.Sin(dlon/2)
+	return units.Distance(2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(h))))
+}
+
+// Length is the fibre length of link l, rounded to the km.
+func (t *Topology) Length(l [2]int) units.Distance {
+	return units.Distance(math.Max(1, math.Round(t.RouteFactor*float64(Distance(t.Nodes[l[0]], t.Nodes[l[1]])))))
+}
+
+// Degree returns the degree of every node.
+func (t *Topology) Degree() []int {
+	d := make([]int, len(t.Nodes))
+	for _, l := range t.Links {
+		d[l[0]]++
+		d[l[1]]++
+	}
+	return d
+}
+
+// Instance returns NODES, LINKS and D of t as an instance.
+func (t *Topology) Instance() *instance.Instance {
+	in := instance.New()
+	for _, n := range t.Nodes {
+		in.Nodes = append(in.Nodes, n.Name)
+	}
+	for _, l := range t.Links {
+		il := instance.Link{I: t.Nodes[l[0]].Name, J: t.Nodes[l[1]].Name}
+		in.Links = append(in.Links, il)
+		in.D[il] = t.Length(l)
+	}
+	return in
+}
+
+// Write writes t as NODES, LINKS and D preceded by a comment with the
+// generator and the node coordinates.
+func (t *Topology) Write(w io.Writer) error {
+	fmt.Fprintf(w, "/* %s topology, %d nodes, %d links, route factor %g.\n   Node coordinates (lat, lon):\n",
+		t.Model, len(t.Nodes), len(t.Links), t.RouteFactor)
+	for _, n := range t.Nodes {
+		fmt.Fprintf(w, "     %s %.4f %.4f\n", n.Name, n.Lat, n.Lon)
+	}
+	fmt.Fprint(w, "*/\ndata;\n\n")
+	if err := t.Instance().WriteTopology(w); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(w, "\nend;")
+	return err
+}
+
+// Generate runs the generator called model: waxman, ba, ring, ladder,
+// grid or gabriel. params holds the model parameters: alpha and beta for
+// waxman, m for ba, cols for grid.
+func Generate(model string, opt Options, params map[string]float64) (*Topology, error) {
+	if opt.N < 1 {
+		return nil, fmt.Errorf("%s: need at least one node", model)
+	}
+	if opt.RouteFactor < 1 {
+		return nil, fmt.Errorf("route factor %g: must be at least 1", opt.RouteFactor)
+	}
+	param := func(name string, def float64) float64 {
+		if v, ok := params[name]; ok {
+			return v
+		}
+		return def
+	}
+	rng := rand.New(rand.NewSource(opt.Seed))
+	var t *Topology
+	switch model {
+	case "waxman":
+		t = Waxman(opt, rng, param("alpha", 0.4), param("beta", 0.4))
+	case "ba":
+		t = BarabasiAlbert(opt, rng, int(param("m", 2)))
+	case "ring":
+		t = Ring(opt)
+	case "ladder":
+		t = Ladder(opt)
+	case "grid":
+		t = Grid(opt, int(param("cols", math.Ceil(math.Sqrt(float64(opt.N))))))
+	case "gabriel":
+		t = Gabriel(opt, rng)
+	default:
+		return nil, fmt.Errorf("unknown topology model %q (have waxman, ba, ring, ladder, grid, gabriel)", model)
+	}
+	t.connect()
+	t.ensureDegree(2)
+	t.removeBridges()
+	t.sortLinks()
+	return t, nil
+}
+
+// newTopology places n nodes named 0..n-1 at the planar positions xy (km
+// from the region's centre).
+func newTopology(model string, opt Options, xy [][2]float64) *Topology {
+	t := &Topology{Model: model, RouteFactor: opt.RouteFactor}
+	for i, p := range xy {
+		lat := opt.Lat + p[1]/earthRadius*180/math.Pi
+		lon := opt.Lon + p[0]/(earthRadius*math.Cos(opt.Lat*math.Pi/180))*180/math.Pi
+		t.Nodes = append(t.Nodes, Node{strconv.Itoa(i), lat, lon})
+	}
+	return t
+}
+
+// scatter draws n uniform positions in the region.
+func scatter(opt Options, rng *rand.Rand) [][2]float64 {
+	xy := make([][2]float64, opt.N)
+	for i := range xy {
+		xy[i] = [2]float64{(rng.Float64() - 0.5) * float64(opt.Span), (rng.Float64() - 0.5) * float64(opt.Span)}
+	}
+	return xy
+}
+
+// xy projects the nodes equirectangularly about their mean latitude, in
+// km, for the geometric tests.
+func (t *Topology) xy() [][2]float64 {
+	lat0 := 0.0
+	for _, n := range t.Nodes {
+		lat0 += n.Lat
+	}
+	lat0 /= float64(max(len(t.Nodes), 1))
+	rad := math.Pi / 180
+	ps := make([][2]float64, len(t.Nodes))
+	for i, n := range t.Nodes {
+		ps[i] = [2]float64{n.Lon * rad * earthRadius * math.Cos(lat0*rad), n.Lat * rad * earthRadius}
+	}
+	return ps
+}
+
+func (t *Topology) has(a, b int) bool {
+	for _, l := range t.Links {
+		if l == [2]int{min(a, b), max(a, b)} {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *Topology) add(a, b int) {
+	if a != b && !t.has(a, b) {
+		t.Links = append(t.Links, [2]int{min(a, b), max(a, b)})
+	}
+}
+
+func (t *Topology) sortLinks() {
+	sort.Slice(t.Links, func(a, b int) bool {
+		if t.Links[a][0] != t.Links[b][0] {
+			return t.Links[a][0] < t.Links[b][0]
+		}
+		return t.Links[a][1] < t.Links[b][1]
+	})
+}
+
+// Waxman links every pair u, v with probability beta exp(-d(u,v) / (alpha
+// L)), L the largest distance, over uniformly scattered nodes.
+func Waxman(opt Options, rng *rand.Rand, alpha, beta float64) *Topology {
+	t := newTopology("waxman", opt, scatter(opt, rng))
+	var l units.Distance
+	for i := range t.Nodes {
+		for j := range i {
+			l = max(l, Distance(t.Nodes[i], t.Nodes[j]))
+		}
+	}
+	for i := range t.Nodes {
+		for j := range i {
+			if rng.Float64() < beta*math.Exp(-float64(Distance(t.Nodes[i], t.Nodes[j])/l)/alpha) {
+				t.add(j, i)
+			}
+		}
+	}
+	return t
+}
+
+// BarabasiAlbert grows a scale-free network by preferential attachment:
+// it starts from a clique of m+1 nodes, and each further node links to m
+// distinct existing nodes chosen with probability proportional to their
+// degree. Nodes are scattered uniformly.
+func BarabasiAlbert(opt Options, rng *rand.Rand, m int) *Topology {
+	t := newTopology("ba", opt, scatter(opt, rng))
+	m = max(1, min(m, opt.N-1))
+	var ends []int // every link end, for sampling by degree
+	for i := 0; i <= m && i < opt.N; i++ {
+		for j := range i {
+			t.add(j, i)
+			ends = append(ends, i, j)
+		}
+	}
+	for i := m + 1; i < opt.N; i++ {
+		chosen := map[int]bool{}
+		for len(chosen) < m {
+			chosen[ends[rng.Intn(len(ends))]] = true
+		}
+		for j := range chosen {
+			t.add(j, i)
+			ends = append(ends, i, j)
+		}
+	}
+	return t
+}
+
+// Ring places the nodes evenly on a circle across the region and links
+// neighbours.
+func Ring(opt Options) *Topology {
+	xy := make([][2]float64, opt.N)
+	for i := range xy {
+		a := 2 * math.Pi * float64(i) / float64(opt.N)
+		xy[i] = [2]float64{float64(opt.Span) / 2 * math.Cos(a), float64(opt.Span) / 2 * math.Sin(a)}
+	}
+	t := newTopology("ring", opt, xy)
+	for i := range t.Nodes {
+		t.add(i, (i+1)%opt.N)
+	}
+	return t
+}
+
+// Ladder places the nodes in two parallel rows across the region, links
+// the neighbours in each row and every pair facing each other.
+func Ladder(opt Options) *Topology {
+	cols := (opt.N + 1) / 2
+	xy := make([][2]float64, opt.N)
+	for i := range xy {
+		x := 0.0
+		if cols > 1 {
+			x = float64(opt.Span) * (float64(i/2)/float64(cols-1) - 0.5)
+		}
+		xy[i] = [2]float64{x, float64(opt.Span) / 4 * float64(1-2*(i%2))}
+	}
+	t := newTopology("ladder", opt, xy)
+	for i := range t.Nodes {
+		if i+2 < opt.N {
+			t.add(i, i+2)
+		}
+		if i%2 == 0 && i+1 < opt.N {
+			t.add(i, i+1)
+		}
+	}
+	return t
+}
+
+// Grid places the nodes row by row on a lattice with cols columns spanning
+// the region and links horizontal and vertical neighbours.
+func Grid(opt Options, cols int) *Topology {
+	cols = max(1, min(cols, opt.N))
+	rows := (opt.N + cols - 1) / cols
+	step := float64(opt.Span) / float64(max(cols-1, rows-1, 1))
+	xy := make([][2]float64, opt.N)
+	for i := range xy {
+		r, c := i/cols, i%cols
+		xy[i] = [2]float64{(float64(c) - float64(cols-1)/2) * step, (float64(r) - float64(rows-1)/2) * step}
+	}
+	t := newTopology("grid", opt, xy)
+	for i := range t.Nodes {
+		if i%cols+1 < cols && i+1 < opt.N {
+			t.add(i, i+1)
+		}
+		if i+cols < opt.N {
+			t.add(i, i+cols)
+		}
+	}
+	return t
+}
+
+// Gabriel links u and v when no other node lies in the disc with diameter
+// uv, over uniformly scattered nodes. The graph is planar and contains the
+// Euclidean minimum spanning tree.
+func Gabriel(opt Options, rng *rand.Rand) *Topology {
+	t := newTopology("gabriel", opt, scatter(opt, rng))
+	ps := t.xy()
+	d2 := func(a, b int) float64 {
+		dx, dy := ps[a][0]-ps[b][0], ps[a][1]-ps[b][1]
+		return dx*dx + dy*dy
+	}
+	for i := range ps {
+	pair:
+		for j := range i {
+			for k := range ps {
+				if k != i && k != j && d2(i, k)+d2(j, k) < d2(i, j) {
+					continue pair
+				}
+			}
+			t.add(j, i)
+		}
+	}
+	return t
+}
+
+// connect joins the components of t, each time by the shortest link
+// between two of them.
+func (t *Topology) connect() {
+	for {
+		comp, n := t.components()
+		if n <= 1 {
+			return
+		}
+		best, bi, bj := units.Distance(math.Inf(1)), -1, -1
+		for i := range t.Nodes {
+			for j := range i {
+				if comp[i] != comp[j] {
+					if d := Distance(t.Nodes[i], t.Nodes[j]); d < best {
+						best, bi, bj = d, i, j
+					}
+				}
+			}
+		}
+		t.add(bj, bi)
+	}
+}
+
+// components labels every node with its component, from 0, and returns
+// the labels and their number.
+func (t *Topology) components() ([]int, int) {
+	adj := make([][]int, len(t.Nodes))
+	for _, l := range t.Links {
+		adj[l[0]] = append(adj[l[0]], l[1])
+		adj[l[1]] = append(adj[l[1]], l[0])
+	}
+	comp := make([]int, len(t.Nodes))
+	for i := range comp {
+		comp[i] = -1
+	}
+	n := 0
+	for s := range t.Nodes {
+		if comp[s] >= 0 {
+			continue
+		}
+		stack := []int{s}
+		comp[s] = n
+		for len(stack) > 0 {
+			u := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for _, v := range adj[u] {
+				if comp[v] < 0 {
+					comp[v] = n
+					stack = append(stack, v)
+				}
+			}
+		}
+		n++
+	}
+	return comp, n
+}
+
+// ensureDegree links every node of degree below k to its nearest
+// non-neighbours, preferring links that cross no existing link so that
+// planar topologies stay planar where possible.
+func (t *Topology) ensureDegree(k int) {
+	if len(t.Nodes) <= k {
+		return
+	}
+	ps := t.xy()
+	for u := range t.Nodes {
+		for t.Degree()[u] < k {
+			var cands []int
+			for v := range t.Nodes {
+				if v != u && !t.has(u, v) {
+					cands = append(cands, v)
+				}
+			}
+			sort.Slice(cands, func(a, b int) bool {
+				return Distance(t.Nodes[u], t.Nodes[cands[a]]) < Distance(t.Nodes[u], t.Nodes[cands[b]])
+			})
+			pick := cands[0]
+			for _, v := range cands {
+				if !t.crosses(ps, u, v) {
+					pick = v
+					break
+				}
+			}
+			t.add(u, pick)
+		}
+	}
+}
+
+// removeBridges adds links until no link of the connected topology t is a
+// bridge. Each bridge gets the shortest link between the two sides it
+// separates, preferring links that cross no existing link, which leaves
+// no new bridge behind.
+func (t *Topology) removeBridges() {
+	if len(t.Nodes) < 3 {
+		return
+	}
+	ps := t.xy()
+	for {
+		side := t.bridge()
+		if side == nil {
+			return
+		}
+		var best [2]int
+		bestLen, crossing := units.Distance(math.Inf(1)), true
+		for u := range t.Nodes {
+			for v := range t.Nodes {
+				if side[u] != 0 || side[v] != 1 || t.has(u, v) {
+					continue
+				}
+				d, c := Distance(t.Nodes[u], t.Nodes[v]), t.crosses(ps, u, v)
+				if crossing && !c || crossing == c && d < bestLen {
+					best, bestLen, crossing = [2]int{u, v}, d, c
+				}
+			}
+		}
+		t.add(best[0], best[1])
+	}
+}
+
+// bridge returns the component labels, 0 and 1, of t without its first
+// bridge, or nil when t has none.
+func (t *Topology) bridge() []int {
+	links := t.Links
+	defer func() { t.Links = links }()
+	for i := range links {
+		t.Links = append(append([][2]int(nil), links[:i]...), links[i+1:]...)
+		if comp, n := t.components(); n > 1 {
+			return comp
+		}
+	}
+	return nil
+}
+
+// crosses reports whether segment uv properly crosses a link of t.
+func (t *Topology) crosses(ps [][2]float64, u, v int) bool {
+	orient := func(a, b, c [2]float64) float64 {
+		return (b[0]-a[0])*(c[1]-a[1]) - (b[1]-a[1])*(c[0]-a[0])
+	}
+	for _, l := range t.Links {
+		if l[0] == u || l[0] == v || l[1] == u || l[1] == v {
+			continue
+		}
+		a, b, c, d := ps[u], ps[v], ps[l[0]], ps[l[1]]
+		if orient(a, b, c)*orient(a, b, d) < 0 && orient(c, d, a)*orient(c, d, b) < 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,34 @@
+package topology
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	params := map[string]float64{"alpha": 0.1, "beta": 0.1, "m": 1}
+	for _, model := range []string{"waxman", "ba", "ring", "ladder", "grid", "gabriel"} {
+		for _, n := range []int{3, 8, 20} {
+			for seed := int64(1); seed <= 5; seed++ {
+				t.Run(fmt.Sprintf("%s/%d/%d", model, n, seed), func(t *testing.T) {
+					opt := DefaultOptions()
+					opt.N, opt.Seed = n, seed
+					top, err := Generate(model, opt, params)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if _, k := top.components(); k != 1 {
+						t.Fatalf("%d components", k)
+					}
+					links := top.Links
+					for i, l := range links {
+						top.Links = append(append([][2]int(nil), links[:i]...), links[i+1:]...)
+						if _, k := top.components(); k != 1 {
+							t.Errorf("link %v is a bridge", l)
+						}
+					}
+				})
+			}
+		}
+	}
+}
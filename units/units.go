@@ -0,0 +1,146 @@
+// Package units gives the physical quantities of the instance model their
+// own types, so that a distance cannot be passed for a reach in metres or
+// a data rate for a slot count, and parses them with their units:
+// "400km", "12.5GHz", "100Gbps", "8slots".
+//
+// Every type stores its value in the unit data.dat uses, which is also the
+// unit of a bare number: km, GHz, Gbps and slots. GMPL has no units, so
+// data.dat itself holds bare numbers and the conversion happens where it
+// is read and written.
+package units
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Distance is a length in km.
+type Distance float64
+
+// DataRate is a bit rate in Gbps.
+type DataRate float64
+
+// Frequency is a bandwidth in GHz.
+type Frequency float64
+
+// Slots is a number of frequency slots.
+type Slots int
+
+// SlotWidth is the width of one frequency slot, the 12.5 GHz granularity
+// of the ITU-T G.694.1 flexible grid.
+const SlotWidth Frequency = 12.5
+
+var (
+	distanceUnits  = map[string]float64{"": 1, "km": 1, "m": 1e-3, "mm": 1e-6, "Mm": 1e3}
+	dataRateUnits  = map[string]float64{"": 1, "Gbps": 1, "Gb/s": 1, "bps": 1e-9, "b/s": 1e-9, "kbps": 1e-6, "kb/s": 1e-6, "Mbps": 1e-3, "Mb/s": 1e-3, "Tbps": 1e3, "Tb/s": 1e3}
+	frequencyUnits = map[string]float64{"": 1, "GHz": 1, "Hz": 1e-9, "kHz": 1e-6, "MHz": 1e-3, "THz": 1e3}
+)
+
+// split separates the number at the start of s from its unit suffix.
+func split(s string) (float64, string, error) {
+	s = strings.TrimSpace(s)
+	i := len(s)
+	for i > 0 && !strings.ContainsRune("0123456789.", rune(s[i-1])) {
+		i--
+	}
+	v, err := strconv.ParseFloat(s[:i], 64)
+	if err != nil {
+		return 0, "", fmt.Errorf("%q: not a number with a unit", s)
+	}
+	return v, strings.TrimSpace(s[i:]), nil
+}
+
+func parse(s, kind string, table map[string]float64) (float64, error) {
+	v, unit, err := split(s)
+	if err != nil {
+		return 0, err
+	}
+	f, ok := table[unit]
+	if !ok {
+		return 0, fmt.Errorf("%q: unknown %s unit %q", s, kind, unit)
+	}
+	return v * f, nil
+}
+
+// ParseDistance parses a distance such as "400km" or "1200 m"; a bare
+// number is in km.
+func ParseDistance(s string) (Distance, error) {
+	v, err := parse(s, "distance", distanceUnits)
+	return Distance(v), err
+}
+
+// ParseDataRate parses a data rate such as "100Gbps" or "400 Mb/s"; a
+// bare number is in Gbps.
+func ParseDataRate(s string) (DataRate, error) {
+	v, err := parse(s, "data rate", dataRateUnits)
+	return DataRate(v), err
+}
+
+// ParseFrequency parses a bandwidth such as "12.5GHz" or "4THz"; a bare
+// number is in GHz.
+func ParseFrequency(s string) (Frequency, error) {
+	v, err := parse(s, "frequency", frequencyUnits)
+	return Frequency(v), err
+}
+
+// ParseSlots parses a slot count: a bare integer, "N slots", or a
+// bandwidth that is a whole number of SlotWidth slots, such as "4.8THz".
+func ParseSlots(s string) (Slots, error) {
+	v, unit, err := split(s)
+	if err != nil {
+		return 0, err
+	}
+	if unit != "" && unit != "slot" && unit != "slots" {
+		f, err := ParseFrequency(s)
+		if err != nil {
+			return 0, fmt.Errorf("%q: want slots or a frequency", s)
+		}
+		return f.Slots()
+	}
+	n := math.Round(v)
+	if math.Abs(v-n) > 1e-9 {
+		return 0, fmt.Errorf("%q: not a whole number of slots", s)
+	}
+	return Slots(n), nil
+}
+
+// Slots converts f into slots of SlotWidth. It fails unless f is a whole
+// number of slots.
+func (f Frequency) Slots() (Slots, error) {
+	n := math.Round(float64(f / SlotWidth))
+	if math.Abs(float64(f/SlotWidth)-n) > 1e-9 {
+		return 0, fmt.Errorf("%v is not a whole number of %v slots", f, SlotWidth)
+	}
+	return Slots(n), nil
+}
+
+// Bandwidth is the spectrum n slots of SlotWidth cover.
+func (n Slots) Bandwidth() Frequency { return Frequency(n) * SlotWidth }
+
+// Scale is d multiplied by the dimensionless factor f.
+func (d Distance) Scale(f float64) Distance { return Distance(float64(d) * f) }
+
+// Scale is r multiplied by the dimensionless factor f.
+func (r DataRate) Scale(f float64) DataRate { return DataRate(float64(r) * f) }
+
+// Efficiency is the spectral efficiency in b/s/Hz of carrying r in f.
+func Efficiency(r DataRate, f Frequency) float64 { return float64(r) / float64(f) }
+
+func (d Distance) String() string  { return strconv.FormatFloat(float64(d), 'g', -1, 64) + "km" }
+func (r DataRate) String() string  { return strconv.FormatFloat(float64(r), 'g', -1, 64) + "Gbps" }
+func (f Frequency) String() string { return strconv.FormatFloat(float64(f), 'g', -1, 64) + "GHz" }
+func (n Slots) String() string     { return strconv.Itoa(int(n)) + "slots" }
+
+// Set parses s into d, making *Distance a flag.Value.
+func (d *Distance) Set(s string) (err error) { *d, err = ParseDistance(s); return }
+
+// Set parses s into r, making *DataRate a flag.Value.
+func (r *DataRate) Set(s string) (err error) { *r, err = ParseDataRate(s); return }
+
+// Set parses s into f, making *Frequency a flag.Value.
+func (f *Frequency) Set(s string) (err error) { *f, err = ParseFrequency(s); return }
+
+// Set parses s into n, making *Slots a flag.Value.
+func (n *Slots) Set(s string) (err error) { *n, err = ParseSlots(s); return }
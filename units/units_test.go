@@ -0,0 +1,85 @@
+package units
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		kind string
+		want float64
+		err  bool
+	}{
+		{"400km", "distance", 400, false},
+		{"1200 m", "distance", 1.2, false},
+		{"350", "distance", 350, false},
+		{"3 furlongs", "distance", 0, true},
+		{"100Gbps", "rate", 100, false},
+		{"400 Mb/s", "rate", 0.4, false},
+		{"1.2Tbps", "rate", 1200, false},
+		{"Gbps", "rate", 0, true},
+		{"12.5GHz", "frequency", 12.5, false},
+		{"4THz", "frequency", 4000, false},
+		{"75 MHz", "frequency", 0.075, false},
+		{"8", "slots", 8, false},
+		{"1 slot", "slots", 1, false},
+		{"320slots", "slots", 320, false},
+		{"4.8THz", "slots", 384, false},
+		{"50GHz", "slots", 4, false},
+		{"2.5", "slots", 0, true},
+		{"20GHz", "slots", 0, true},
+		{"4km", "slots", 0, true},
+	}
+	for _, tt := range tests {
+		var got float64
+		var err error
+		switch tt.kind {
+		case "distance":
+			var v Distance
+			v, err = ParseDistance(tt.in)
+			got = float64(v)
+		case "rate":
+			var v DataRate
+			v, err = ParseDataRate(tt.in)
+			got = float64(v)
+		case "frequency":
+			var v Frequency
+			v, err = ParseFrequency(tt.in)
+			got = float64(v)
+		case "slots":
+			var v Slots
+			v, err = ParseSlots(tt.in)
+			got = float64(v)
+		}
+		switch {
+		case tt.err && err == nil:
+			t.Errorf("%s %q: got %g, want an error", tt.kind, tt.in, got)
+		case !tt.err && err != nil:
+			t.Errorf("%s %q: %v", tt.kind, tt.in, err)
+		case !tt.err && math.Abs(got-tt.want) > 1e-9*tt.want:
+			t.Errorf("%s %q = %g, want %g", tt.kind, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		v    interface{ String() string }
+		want string
+	}{
+		{Distance(400), "400km"},
+		{DataRate(100), "100Gbps"},
+		{SlotWidth, "12.5GHz"},
+		{Slots(8), "8slots"},
+		{Slots(4).Bandwidth(), "50GHz"},
+		{Distance(400).Scale(1.5), "600km"},
+		{DataRate(100).Scale(0.25), "25Gbps"},
+	}
+	for _, tt := range tests {
+		if got := tt.v.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}